@@ -3,21 +3,18 @@ module github.com/hansdq/go-okx
 go 1.20
 
 require (
+	github.com/gorilla/websocket v1.4.2
 	github.com/hansdq/recws v0.0.0-20240510050643-b32da768073f
 	github.com/pkg/errors v0.9.1
-	github.com/tidwall/gjson v1.14.4
 	github.com/zeromicro/go-zero v1.6.3
 )
 
 require (
 	github.com/fatih/color v1.16.0 // indirect
-	github.com/gorilla/websocket v1.4.2 // indirect
 	github.com/jpillora/backoff v1.0.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/spaolacci/murmur3 v1.1.0 // indirect
-	github.com/tidwall/match v1.1.1 // indirect
-	github.com/tidwall/pretty v1.2.0 // indirect
 	go.opentelemetry.io/otel v1.19.0 // indirect
 	go.opentelemetry.io/otel/trace v1.19.0 // indirect
 	go.uber.org/automaxprocs v1.5.3 // indirect
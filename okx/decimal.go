@@ -0,0 +1,256 @@
+package okx
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal 精确的十进制数，用于价格、数量和余额的计算
+// 零值表示空值（OKX返回的空字符串），参与运算时按0处理
+type Decimal struct {
+	value *big.Int // 未缩放的整数值，nil表示空值
+	scale int32    // 小数位数
+}
+
+var ten = big.NewInt(10)
+
+// NewDecimal 返回value * 10^-scale
+func NewDecimal(value int64, scale int32) Decimal {
+	return Decimal{value: big.NewInt(value), scale: scale}
+}
+
+// ParseDecimal 解析十进制字符串，支持科学计数法，空字符串返回空值
+func ParseDecimal(s string) (Decimal, error) {
+	if s == "" {
+		return Decimal{}, nil
+	}
+
+	mantissa, exp := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(s[i+1:], 10, 32); err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+		}
+		mantissa = s[:i]
+	}
+
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	digits := intPart + fracPart
+	if digits == "" || digits == "-" || digits == "+" || strings.ContainsAny(fracPart, "+-") {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+	}
+
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+	}
+
+	scale := int64(len(fracPart)) - exp
+	if scale < 0 {
+		value.Mul(value, pow10(-scale))
+		scale = 0
+	}
+
+	return Decimal{value: value, scale: int32(scale)}, nil
+}
+
+// MustDecimal 同ParseDecimal，解析失败时panic，仅用于常量
+func MustDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(ten, big.NewInt(n), nil)
+}
+
+func (d Decimal) int() *big.Int {
+	if d.value == nil {
+		return new(big.Int)
+	}
+
+	return d.value
+}
+
+// rescale 返回放大到scale位小数的整数值，scale不能小于d.scale
+func (d Decimal) rescale(scale int32) *big.Int {
+	v := new(big.Int).Set(d.int())
+	if scale > d.scale {
+		v.Mul(v, pow10(int64(scale-d.scale)))
+	}
+
+	return v
+}
+
+func align(a, b Decimal) (*big.Int, *big.Int, int32) {
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+
+	return a.rescale(scale), b.rescale(scale), scale
+}
+
+// IsEmpty 是否为空值
+func (d Decimal) IsEmpty() bool {
+	return d.value == nil
+}
+
+// IsZero 是否为0，空值也视为0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Sign 返回-1、0或1
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// Cmp 比较d和o，d小于、等于、大于o时分别返回-1、0、1
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, _ := align(d, o)
+	return a.Cmp(b)
+}
+
+// Equal 数值是否相等，不比较小数位数
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return Decimal{value: a.Add(a, b), scale: scale}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return Decimal{value: a.Sub(a, b), scale: scale}
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{value: new(big.Int).Mul(d.int(), o.int()), scale: d.scale + o.scale}
+}
+
+// Div 返回d/o，结果保留places位小数并向零截断，o为0时panic
+func (d Decimal) Div(o Decimal, places int32) Decimal {
+	if o.IsZero() {
+		panic("okx: decimal division by zero")
+	}
+
+	// d/o = (dv * 10^(places + os - ds)) / ov * 10^-places
+	shift := int64(places) + int64(o.scale) - int64(d.scale)
+	num := new(big.Int).Set(d.int())
+	den := new(big.Int).Set(o.int())
+	if shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+
+	return Decimal{value: num.Quo(num, den), scale: places}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Truncate 保留places位小数，多余的部分向零截断，常用于按lotSz或tickSz取整
+func (d Decimal) Truncate(places int32) Decimal {
+	if places >= d.scale {
+		return Decimal{value: d.rescale(places), scale: places}
+	}
+
+	v := new(big.Int).Quo(d.int(), pow10(int64(d.scale-places)))
+	return Decimal{value: v, scale: places}
+}
+
+// Round 保留places位小数，四舍五入
+func (d Decimal) Round(places int32) Decimal {
+	if places >= d.scale {
+		return Decimal{value: d.rescale(places), scale: places}
+	}
+
+	div := pow10(int64(d.scale - places))
+	q, r := new(big.Int).QuoRem(d.int(), div, new(big.Int))
+	if r.Abs(r).Lsh(r, 1).Cmp(div) >= 0 {
+		if d.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	return Decimal{value: q, scale: places}
+}
+
+// String 返回十进制字符串，空值返回空字符串
+func (d Decimal) String() string {
+	if d.value == nil {
+		return ""
+	}
+
+	s := new(big.Int).Abs(d.value).String()
+	if d.scale > 0 {
+		if len(s) <= int(d.scale) {
+			s = strings.Repeat("0", int(d.scale)-len(s)+1) + s
+		}
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+
+	if d.value.Sign() < 0 {
+		s = "-" + s
+	}
+
+	return s
+}
+
+// StringFixed 返回保留places位小数的字符串
+func (d Decimal) StringFixed(places int32) string {
+	return d.Round(places).String()
+}
+
+// Float64 返回最接近的float64，仅用于展示或近似计算
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON 支持字符串、空字符串、数字和null
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		*d = Decimal{}
+		return nil
+	}
+
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return fmt.Errorf("invalid decimal: %s", b)
+		}
+	}
+
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+
+	*d = v
+	return nil
+}
@@ -0,0 +1,122 @@
+package okx
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"0.1", "0.1", true},
+		{"-12.340", "-12.340", true},
+		{".5", "0.5", true},
+		{"1e-8", "0.00000001", true},
+		{"1.5E3", "1500", true},
+		{"", "", true},
+		{"abc", "", false},
+		{"1.2.3", "", false},
+		{"1e", "", false},
+		{"-", "", false},
+	}
+
+	for _, c := range cases {
+		d, err := ParseDecimal(c.in)
+		if (err == nil) != c.ok {
+			t.Errorf("ParseDecimal(%q) err = %v", c.in, err)
+			continue
+		}
+		if c.ok && d.String() != c.want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", c.in, d, c.want)
+		}
+	}
+}
+
+func TestDecimalArith(t *testing.T) {
+	a, b := MustDecimal("0.1"), MustDecimal("0.2")
+	if got := a.Add(b); !got.Equal(MustDecimal("0.3")) {
+		t.Errorf("0.1 + 0.2 = %s", got)
+	}
+
+	if got := MustDecimal("3.5").Mul(MustDecimal("0.01")).String(); got != "0.035" {
+		t.Errorf("3.5 * 0.01 = %s", got)
+	}
+
+	if got := MustDecimal("10").Div(MustDecimal("3"), 4).String(); got != "3.3333" {
+		t.Errorf("10 / 3 = %s", got)
+	}
+
+	if got := MustDecimal("2.345").Round(2).String(); got != "2.35" {
+		t.Errorf("round(2.345) = %s", got)
+	}
+
+	if got := MustDecimal("-2.345").Round(2).String(); got != "-2.35" {
+		t.Errorf("round(-2.345) = %s", got)
+	}
+
+	if got := MustDecimal("2.349").Truncate(2).String(); got != "2.34" {
+		t.Errorf("truncate(2.349) = %s", got)
+	}
+
+	var empty Decimal
+	if got := empty.Add(a); !got.Equal(a) || !empty.IsZero() {
+		t.Errorf("empty + 0.1 = %s", got)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		Px  Decimal `json:"px"`
+		Sz  Decimal `json:"sz"`
+		Fee Decimal `json:"fee"`
+		Pnl Decimal `json:"pnl"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"px":"27000.1","sz":"","fee":-0.02,"pnl":null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Px.String() != "27000.1" || !v.Sz.IsEmpty() || v.Fee.String() != "-0.02" || !v.Pnl.IsEmpty() {
+		t.Errorf("unmarshal = %+v", v)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"px":"27000.1","sz":"","fee":"-0.02","pnl":""}`; string(b) != want {
+		t.Errorf("marshal = %s, want %s", b, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"px":"1,000"}`), &v); err == nil {
+		t.Error("expected error for malformed px")
+	}
+}
+
+func TestParseBookOrder(t *testing.T) {
+	order, err := parseBookOrder([]string{"0.1", "0.3", "0", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order.Notional.String() != "0.03" || order.OrderQuantity != 2 {
+		t.Errorf("order = %+v", order)
+	}
+
+	if _, err := parseBookOrder([]string{"0.1", "x", "0", "2"}); err == nil {
+		t.Error("expected error for malformed sz")
+	}
+
+	candle, err := parseCandle([]string{"1700000000000", "1", "2", "0.5", "1.5", "10", "15", "15", "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if candle.Confirm != 1 || candle.ClosePx.String() != "1.5" {
+		t.Errorf("candle = %+v", candle)
+	}
+}
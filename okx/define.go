@@ -1,7 +1,18 @@
 package okx
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 const (
-	TimeMaxDiff = 2000 // 时间误差最大值，大于此值不能够下单
+	TimeMaxDiff             = 2000            // 时间误差最大值，大于此值不能够下单
+	DefaultTimeout          = 5 * time.Second // rest请求默认超时时间
+	DefaultTimeSyncInterval = 5 * time.Minute // 默认的交易所时间偏移刷新间隔
+
+	DefaultHeartbeatInterval = 20 * time.Second // 超过此时间没有收到消息时发送ping，交易所30秒无消息会断开连接
+	DefaultHeartbeatTimeout  = 10 * time.Second // 发送ping后等待pong的时间，超时视为连接已断开
 )
 
 // 实盘
@@ -14,11 +25,105 @@ const (
 
 // 模拟盘
 const (
-	RestSimulateUrl = "https://www.okx.com"
-	SocketSimPubUrl = "wss://wspap.okx.com:8443/ws/v5/public?brokerId=9999"
-	SocketSimPriUrl = "wss://wspap.okx.com:8443/ws/v5/private?brokerId=9999"
+	RestSimulateUrl      = "https://www.okx.com"
+	SocketSimPubUrl      = "wss://wspap.okx.com:8443/ws/v5/public?brokerId=9999"
+	SocketSimPriUrl      = "wss://wspap.okx.com:8443/ws/v5/private?brokerId=9999"
+	SocketSimBusinessUrl = "wss://wspap.okx.com:8443/ws/v5/business?brokerId=9999"
+)
+
+// 区域
+const (
+	LiveRegion    = "live"     // 实盘
+	AwsRegion     = "aws"      // 实盘AWS
+	DemoRegion    = "demo"     // 模拟盘
+	EeaRegion     = "eea"      // 欧洲经济区实盘
+	EeaDemoRegion = "eea-demo" // 欧洲经济区模拟盘
+	UsRegion      = "us"       // 美国实盘
+	UsDemoRegion  = "us-demo"  // 美国模拟盘
 )
 
+// Endpoint 一个区域的rest和websocket地址，可以单独修改其中的任意地址
+type Endpoint struct {
+	Rest     string
+	Public   string // 公共频道
+	Private  string // 私有频道
+	Business string // 业务频道
+	Simulate bool   // 模拟盘标识
+}
+
+// Endpoints 各区域的地址
+var Endpoints = map[string]Endpoint{
+	LiveRegion: {
+		Rest:     "https://www.okx.com",
+		Public:   "wss://ws.okx.com:8443/ws/v5/public",
+		Private:  "wss://ws.okx.com:8443/ws/v5/private",
+		Business: "wss://ws.okx.com:8443/ws/v5/business",
+	},
+	AwsRegion: {
+		Rest:     "https://aws.okx.com",
+		Public:   "wss://wsaws.okx.com:8443/ws/v5/public",
+		Private:  "wss://wsaws.okx.com:8443/ws/v5/private",
+		Business: "wss://wsaws.okx.com:8443/ws/v5/business",
+	},
+	DemoRegion: {
+		Rest:     RestSimulateUrl,
+		Public:   SocketSimPubUrl,
+		Private:  SocketSimPriUrl,
+		Business: SocketSimBusinessUrl,
+		Simulate: true,
+	},
+	EeaRegion: {
+		Rest:     "https://eea.okx.com",
+		Public:   "wss://wseea.okx.com:8443/ws/v5/public",
+		Private:  "wss://wseea.okx.com:8443/ws/v5/private",
+		Business: "wss://wseea.okx.com:8443/ws/v5/business",
+	},
+	EeaDemoRegion: {
+		Rest:     "https://eea.okx.com",
+		Public:   "wss://wseeapap.okx.com:8443/ws/v5/public",
+		Private:  "wss://wseeapap.okx.com:8443/ws/v5/private",
+		Business: "wss://wseeapap.okx.com:8443/ws/v5/business",
+		Simulate: true,
+	},
+	UsRegion: {
+		Rest:     "https://us.okx.com",
+		Public:   "wss://wsus.okx.com:8443/ws/v5/public",
+		Private:  "wss://wsus.okx.com:8443/ws/v5/private",
+		Business: "wss://wsus.okx.com:8443/ws/v5/business",
+	},
+	UsDemoRegion: {
+		Rest:     "https://us.okx.com",
+		Public:   "wss://wsuspap.okx.com:8443/ws/v5/public",
+		Private:  "wss://wsuspap.okx.com:8443/ws/v5/private",
+		Business: "wss://wsuspap.okx.com:8443/ws/v5/business",
+		Simulate: true,
+	},
+}
+
+// GetEndpoint 根据区域名称获取地址
+func GetEndpoint(region string) (Endpoint, error) {
+	e, ok := Endpoints[region]
+	if !ok {
+		return Endpoint{}, fmt.Errorf("unknown region: %s", region)
+	}
+
+	return e, nil
+}
+
+// DefaultEndpoint 未指定区域时使用的地址
+func DefaultEndpoint(simulate bool) Endpoint {
+	if simulate {
+		return Endpoints[DemoRegion]
+	}
+
+	return Endpoint{
+		Rest:     RestGlobalUrl,
+		Public:   SocketPubUrl,
+		Private:  SocketPriGlobalUrl,
+		Business: SocketBusinessUrl,
+	}
+}
+
 // public url
 const (
 	InstrumentsUrl = "/api/v5/public/instruments"
@@ -69,9 +174,17 @@ const (
 	BatchOrdersUrl       = "/api/v5/trade/batch-orders"
 	ClosePositionUrl     = "/api/v5/trade/close-position"
 	CancelOrderUrl       = "/api/v5/trade/cancel-order"
+	CancelBatchOrdersUrl = "/api/v5/trade/cancel-batch-orders"
+	MassCancelUrl        = "/api/v5/trade/mass-cancel"      // 按产品族撤销所有期权订单
+	CancelAllAfterUrl    = "/api/v5/trade/cancel-all-after" // 倒计时结束后撤销所有订单
+	AmendOrderUrl        = "/api/v5/trade/amend-order"
+	AmendBatchOrdersUrl  = "/api/v5/trade/amend-batch-orders"
 	OrdersPendingUrl     = "/api/v5/trade/orders-pending"
 	PostOrderAlgo        = "/api/v5/trade/order-algo"   // 包含止盈止损的下单
 	PostCancelOrderAlgos = "/api/v5/trade/cancel-algos" // 撤销策略订单
+	AmendAlgosUrl        = "/api/v5/trade/amend-algos"
+	OrdersAlgoPendingUrl = "/api/v5/trade/orders-algo-pending"
+	OrdersAlgoHistoryUrl = "/api/v5/trade/orders-algo-history"
 )
 
 // 时间粒度
@@ -129,26 +242,57 @@ const (
 
 // 普通委托订单类型
 const (
-	Market   = "market"
-	Limit    = "limit"
-	PostOnly = "post_only"
+	Market          = "market"
+	Limit           = "limit"
+	PostOnly        = "post_only"
+	Fok             = "fok"               // 全部成交或立即取消
+	Ioc             = "ioc"               // 立即成交并取消剩余
+	OptimalLimitIoc = "optimal_limit_ioc" // 市价委托立即成交并取消剩余，仅适用于交割、永续
+)
+
+// 订单状态
+const (
+	Live            = "live"             // 等待成交
+	PartiallyFilled = "partially_filled" // 部分成交
+	Filled          = "filled"           // 完全成交
+	Canceled        = "canceled"         // 撤单成功
 )
 
 // 条件委托订单类型
 const (
-	Condition     = "condition"       // 单向止盈止损
+	Conditional   = "conditional"     // 单向止盈止损
 	Oco           = "oco"             // 双向止盈止损
 	Plan          = "trigger"         // 计划委托
 	MoveOrderStop = "move_order_stop" // 移动止盈止损
 	Iceberg       = "iceberg"         // 冰山委托
 	Twap          = "twap"            // 时间加权
+
+	// Deprecated: 交易所不接受此值，单向止盈止损使用Conditional
+	Condition = "condition"
+)
+
+// 策略委托订单状态
+const (
+	AlgoLive               = "live"                // 待生效
+	AlgoPause              = "pause"               // 暂停生效
+	AlgoPartiallyEffective = "partially_effective" // 部分生效
+	AlgoEffective          = "effective"           // 已生效
+	AlgoCanceled           = "canceled"            // 已撤销
+	AlgoOrderFailed        = "order_failed"        // 委托失败
 )
 
 // 交易模式
 const (
-	Isolated = "isolated" // 逐仓
-	Cross    = "cross"    // 全仓
-	Cash     = "cash"
+	Isolated     = "isolated" // 逐仓
+	Cross        = "cross"    // 全仓
+	Cash         = "cash"
+	SpotIsolated = "spot_isolated" // 现货逐仓，仅适用于带单
+)
+
+// 合约类型
+const (
+	Linear  = "linear"  // 正向合约
+	Inverse = "inverse" // 反向合约
 )
 
 // 方向
@@ -157,6 +301,7 @@ const (
 	Sell      = "sell"
 	MakeLong  = "long"
 	MakeShort = "short"
+	Net       = "net" // 买卖模式下的持仓方向
 )
 
 // 市价单委托数量的类型
@@ -172,11 +317,82 @@ const (
 	Mark  = "mark"  // 标记价格
 )
 
-// websocket
+// websocket频道
 const (
-	CandleChannel = "candle"
+	CandleChannel             = "candle" // k线频道的前缀，如candle1m，使用业务频道地址
+	MarkPriceCandleChannel    = "mark-price-candle"
+	IndexCandleChannel        = "index-candle"
+	TradesChannel             = "trades"
+	TradesAllChannel          = "trades-all" // 逐笔成交，不聚合，使用业务频道地址
+	TickersChannel            = "tickers"
+	BooksChannel              = "books"
+	MarkPriceChannel          = "mark-price"
+	IndexTickersChannel       = "index-tickers"
+	FundingRateChannel        = "funding-rate"
+	OpenInterestChannel       = "open-interest"
+	OrdersChannel             = "orders"
+	PositionsChannel          = "positions"
+	AccountChannel            = "account"
+	BalanceAndPositionChannel = "balance_and_position"
 )
 
+// privateChannels 需要登录的频道
+var privateChannels = map[string]bool{
+	OrdersChannel:             true,
+	PositionsChannel:          true,
+	AccountChannel:            true,
+	BalanceAndPositionChannel: true,
+}
+
+// publicChannels 已知的公共频道
+var publicChannels = map[string]bool{
+	TradesChannel:       true,
+	TickersChannel:      true,
+	BooksChannel:        true,
+	"books5":            true,
+	"bbo-tbt":           true,
+	"books-l2-tbt":      true,
+	"books50-l2-tbt":    true,
+	MarkPriceChannel:    true,
+	IndexTickersChannel: true,
+	FundingRateChannel:  true,
+	OpenInterestChannel: true,
+}
+
+// channelKind 返回频道所属的地址类型public、private或business，未知的频道返回空字符串
+func channelKind(channel string) string {
+	switch {
+	case IsBusinessChannel(channel):
+		return "business"
+	case privateChannels[channel]:
+		return "private"
+	case publicChannels[channel]:
+		return "public"
+	}
+
+	return ""
+}
+
+// IsBusinessChannel 是否为需要使用业务频道地址的频道
+func IsBusinessChannel(channel string) bool {
+	return strings.HasPrefix(channel, CandleChannel) ||
+		strings.HasPrefix(channel, MarkPriceCandleChannel) ||
+		strings.HasPrefix(channel, IndexCandleChannel) ||
+		channel == TradesAllChannel
+}
+
+// ChannelURL 返回channel对应的websocket地址
+func (e Endpoint) ChannelURL(channel string) string {
+	switch {
+	case IsBusinessChannel(channel):
+		return e.Business
+	case privateChannels[channel]:
+		return e.Private
+	}
+
+	return e.Public
+}
+
 // 持仓模式
 const (
 	LongShortMode = "long_short_mode"
@@ -0,0 +1,266 @@
+package okx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// maxCancelAlgos 撤销策略委托每次请求的最大订单数
+const maxCancelAlgos = 10
+
+// AlgoOrderResult 策略委托下单、撤单和修改的结果
+type AlgoOrderResult struct {
+	AlgoId      string `json:"algoId"`
+	AlgoClOrdId string `json:"algoClOrdId"`
+	ReqId       string `json:"reqId"` // 仅修改时返回
+	Tag         string `json:"tag"`
+	SCode       string `json:"sCode"`
+	SMsg        string `json:"sMsg"`
+}
+
+// AlgoOrder 策略委托订单
+type AlgoOrder struct {
+	InstType        string   `json:"instType"`
+	InstId          string   `json:"instId"`
+	Ccy             string   `json:"ccy"`
+	OrdId           string   `json:"ordId"`
+	OrdIdList       []string `json:"ordIdList"` // 触发后生成的订单ID
+	AlgoId          string   `json:"algoId"`
+	AlgoClOrdId     string   `json:"algoClOrdId"`
+	Sz              string   `json:"sz"`
+	CloseFraction   string   `json:"closeFraction"`
+	OrdType         string   `json:"ordType"`
+	Side            string   `json:"side"`
+	PosSide         string   `json:"posSide"`
+	TdMode          string   `json:"tdMode"`
+	TgtCcy          string   `json:"tgtCcy"`
+	State           string   `json:"state"`
+	Lever           string   `json:"lever"`
+	TpTriggerPx     string   `json:"tpTriggerPx"`
+	TpTriggerPxType string   `json:"tpTriggerPxType"`
+	TpOrdPx         string   `json:"tpOrdPx"`
+	SlTriggerPx     string   `json:"slTriggerPx"`
+	SlTriggerPxType string   `json:"slTriggerPxType"`
+	SlOrdPx         string   `json:"slOrdPx"`
+	TriggerPx       string   `json:"triggerPx"`
+	TriggerPxType   string   `json:"triggerPxType"`
+	OrdPx           string   `json:"ordPx"`
+	ActualSz        string   `json:"actualSz"`   // 实际委托数量
+	ActualPx        string   `json:"actualPx"`   // 实际委托价
+	ActualSide      string   `json:"actualSide"` // 实际触发方向，tp或sl
+	TriggerTime     string   `json:"triggerTime"`
+	PxVar           string   `json:"pxVar"`
+	PxSpread        string   `json:"pxSpread"`
+	SzLimit         string   `json:"szLimit"`
+	PxLimit         string   `json:"pxLimit"`
+	TimeInterval    string   `json:"timeInterval"`
+	CallbackRatio   string   `json:"callbackRatio"`
+	CallbackSpread  string   `json:"callbackSpread"`
+	ActivePx        string   `json:"activePx"`
+	MoveTriggerPx   string   `json:"moveTriggerPx"` // 移动止盈止损的触发价
+	ReduceOnly      string   `json:"reduceOnly"`
+	Last            string   `json:"last"`     // 下单时的最新成交价
+	FailCode        string   `json:"failCode"` // 委托失败的错误码
+	Tag             string   `json:"tag"`
+	CTime           string   `json:"cTime"`
+	UTime           string   `json:"uTime"`
+}
+
+// PlaceConditionOrder 单向止盈止损，tp和sl至少设置一个
+func (c *RestConfig) PlaceConditionOrder(req *TpSlOrderRequest) (*AlgoOrderResult, error) {
+	return c.PlaceConditionOrderWithContext(context.Background(), req)
+}
+
+// PlaceConditionOrderWithContext 同PlaceConditionOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) PlaceConditionOrderWithContext(ctx context.Context, req *TpSlOrderRequest) (*AlgoOrderResult, error) {
+	r := *req
+	r.OrdType = Conditional
+	return c.placeAlgoOrder(ctx, &r)
+}
+
+// PlaceOcoOrder 双向止盈止损，tp和sl都需要设置，一个触发后另一个自动撤销
+func (c *RestConfig) PlaceOcoOrder(req *TpSlOrderRequest) (*AlgoOrderResult, error) {
+	return c.PlaceOcoOrderWithContext(context.Background(), req)
+}
+
+// PlaceOcoOrderWithContext 同PlaceOcoOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) PlaceOcoOrderWithContext(ctx context.Context, req *TpSlOrderRequest) (*AlgoOrderResult, error) {
+	r := *req
+	r.OrdType = Oco
+	return c.placeAlgoOrder(ctx, &r)
+}
+
+// PlaceTriggerOrder 计划委托
+func (c *RestConfig) PlaceTriggerOrder(req *TriggerOrderRequest) (*AlgoOrderResult, error) {
+	return c.PlaceTriggerOrderWithContext(context.Background(), req)
+}
+
+// PlaceTriggerOrderWithContext 同PlaceTriggerOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) PlaceTriggerOrderWithContext(ctx context.Context, req *TriggerOrderRequest) (*AlgoOrderResult, error) {
+	r := *req
+	r.OrdType = Plan
+	return c.placeAlgoOrder(ctx, &r)
+}
+
+// PlaceMoveOrderStop 移动止盈止损
+func (c *RestConfig) PlaceMoveOrderStop(req *MoveOrderStopRequest) (*AlgoOrderResult, error) {
+	return c.PlaceMoveOrderStopWithContext(context.Background(), req)
+}
+
+// PlaceMoveOrderStopWithContext 同PlaceMoveOrderStop，使用ctx控制请求的取消与超时
+func (c *RestConfig) PlaceMoveOrderStopWithContext(ctx context.Context, req *MoveOrderStopRequest) (*AlgoOrderResult, error) {
+	r := *req
+	r.OrdType = MoveOrderStop
+	return c.placeAlgoOrder(ctx, &r)
+}
+
+// PlaceIcebergOrder 冰山委托
+func (c *RestConfig) PlaceIcebergOrder(req *IcebergOrderRequest) (*AlgoOrderResult, error) {
+	return c.PlaceIcebergOrderWithContext(context.Background(), req)
+}
+
+// PlaceIcebergOrderWithContext 同PlaceIcebergOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) PlaceIcebergOrderWithContext(ctx context.Context, req *IcebergOrderRequest) (*AlgoOrderResult, error) {
+	r := *req
+	r.OrdType = Iceberg
+	return c.placeAlgoOrder(ctx, &r)
+}
+
+// PlaceTwapOrder 时间加权委托
+func (c *RestConfig) PlaceTwapOrder(req *TwapOrderRequest) (*AlgoOrderResult, error) {
+	return c.PlaceTwapOrderWithContext(context.Background(), req)
+}
+
+// PlaceTwapOrderWithContext 同PlaceTwapOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) PlaceTwapOrderWithContext(ctx context.Context, req *TwapOrderRequest) (*AlgoOrderResult, error) {
+	r := *req
+	r.OrdType = Twap
+	return c.placeAlgoOrder(ctx, &r)
+}
+
+func (c *RestConfig) placeAlgoOrder(ctx context.Context, req interface{ Validate() error }) (*AlgoOrderResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var results []*AlgoOrderResult
+	_, err := c.request(ctx, req, &results, http.MethodPost, PostOrderAlgo, "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	return results[0], nil
+}
+
+// CancelAlgoOrders 批量撤销策略委托，超过10个时分多次请求
+// 部分失败时同时返回结果和*APIError，APIError.Items中的Index为在reqs中的位置，中途请求整体失败时返回*BatchError
+func (c *RestConfig) CancelAlgoOrders(reqs []*CancelAlgoRequest) ([]*AlgoOrderResult, error) {
+	return c.CancelAlgoOrdersWithContext(context.Background(), reqs)
+}
+
+// CancelAlgoOrdersWithContext 同CancelAlgoOrders，使用ctx控制请求的取消与超时
+func (c *RestConfig) CancelAlgoOrdersWithContext(ctx context.Context, reqs []*CancelAlgoRequest) ([]*AlgoOrderResult, error) {
+	for _, req := range reqs {
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return requestChunked[*CancelAlgoRequest, *AlgoOrderResult](ctx, c, PostCancelOrderAlgos, reqs, maxCancelAlgos)
+}
+
+// AmendAlgoOrder 修改未触发的止盈止损或计划委托
+func (c *RestConfig) AmendAlgoOrder(req *AmendAlgoRequest) (*AlgoOrderResult, error) {
+	return c.AmendAlgoOrderWithContext(context.Background(), req)
+}
+
+// AmendAlgoOrderWithContext 同AmendAlgoOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) AmendAlgoOrderWithContext(ctx context.Context, req *AmendAlgoRequest) (*AlgoOrderResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var results []*AlgoOrderResult
+	_, err := c.request(ctx, req, &results, http.MethodPost, AmendAlgosUrl, "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	return results[0], nil
+}
+
+// ListAlgoOrdersPending 获取未完成的策略委托
+func (c *RestConfig) ListAlgoOrdersPending(req *AlgoOrdersRequest) ([]*AlgoOrder, error) {
+	return c.ListAlgoOrdersPendingWithContext(context.Background(), req)
+}
+
+// ListAlgoOrdersPendingWithContext 同ListAlgoOrdersPending，使用ctx控制请求的取消与超时
+func (c *RestConfig) ListAlgoOrdersPendingWithContext(ctx context.Context, req *AlgoOrdersRequest) ([]*AlgoOrder, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if req.State != "" {
+		return nil, fmt.Errorf("state is only supported when listing history")
+	}
+
+	return c.listAlgoOrders(ctx, OrdersAlgoPendingUrl, req)
+}
+
+// ListAlgoOrdersHistory 获取最近三个月的历史策略委托
+func (c *RestConfig) ListAlgoOrdersHistory(req *AlgoOrdersRequest) ([]*AlgoOrder, error) {
+	return c.ListAlgoOrdersHistoryWithContext(context.Background(), req)
+}
+
+// ListAlgoOrdersHistoryWithContext 同ListAlgoOrdersHistory，使用ctx控制请求的取消与超时
+func (c *RestConfig) ListAlgoOrdersHistoryWithContext(ctx context.Context, req *AlgoOrdersRequest) ([]*AlgoOrder, error) {
+	if err := firstError(req.Validate(), checkRequired("state or algoId", req.State+req.AlgoId)); err != nil {
+		return nil, err
+	}
+
+	return c.listAlgoOrders(ctx, OrdersAlgoHistoryUrl, req)
+}
+
+func (c *RestConfig) listAlgoOrders(ctx context.Context, path string, req *AlgoOrdersRequest) ([]*AlgoOrder, error) {
+	var orders []*AlgoOrder
+	_, err := c.request(ctx, nil, &orders, http.MethodGet, fmt.Sprintf("%s?%s", path, encodeQuery(req)), "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// AlgoOrderDetail 查询策略委托详情，algoId和algoClOrdId传一个
+func (c *RestConfig) AlgoOrderDetail(algoId, algoClOrdId string) (*AlgoOrder, error) {
+	return c.AlgoOrderDetailWithContext(context.Background(), algoId, algoClOrdId)
+}
+
+// AlgoOrderDetailWithContext 同AlgoOrderDetail，使用ctx控制请求的取消与超时
+func (c *RestConfig) AlgoOrderDetailWithContext(ctx context.Context, algoId, algoClOrdId string) (*AlgoOrder, error) {
+	if err := checkRequired("algoId or algoClOrdId", algoId+algoClOrdId); err != nil {
+		return nil, err
+	}
+
+	data := url.Values{}
+	if algoId != "" {
+		data.Set("algoId", algoId)
+	}
+	if algoClOrdId != "" {
+		data.Set("algoClOrdId", algoClOrdId)
+	}
+
+	var orders []*AlgoOrder
+	_, err := c.request(ctx, nil, &orders, http.MethodGet, fmt.Sprintf("%s?%s", PostOrderAlgo, data.Encode()), "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("algo order not found")
+	}
+
+	return orders[0], nil
+}
@@ -0,0 +1,102 @@
+package okx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPlaceAlgoOrder(t *testing.T) {
+	var requests []string
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+string(b))
+		if r.Method == http.MethodGet {
+			return stubResponse(http.StatusOK, `{"code":"0","msg":"","data":[{"algoId":"7","instId":"BTC-USDT","ordType":"twap","state":"live","pxVar":"0.01","timeInterval":"10"}]}`), nil
+		}
+		return stubResponse(http.StatusOK, `{"code":"0","msg":"","data":[{"algoId":"7","algoClOrdId":"b1","sCode":"0","sMsg":""}]}`), nil
+	})
+
+	req := &TpSlOrderRequest{
+		AlgoOrderRequest: AlgoOrderRequest{InstId: "BTC-USDT", TdMode: Cash, Side: Sell, Sz: "1", AlgoClOrdId: "b1"},
+		TpTriggerPx:      "45000",
+		TpOrdPx:          "-1",
+		SlTriggerPx:      "39000",
+		SlOrdPx:          "-1",
+	}
+	res, err := c.PlaceOcoOrder(req)
+	if err != nil || res.AlgoId != "7" {
+		t.Fatalf("res = %+v, err = %v", res, err)
+	}
+
+	want := `POST ` + PostOrderAlgo + ` {"instId":"BTC-USDT","tdMode":"cash","side":"sell","ordType":"oco","sz":"1","algoClOrdId":"b1","tpTriggerPx":"45000","tpOrdPx":"-1","slTriggerPx":"39000","slOrdPx":"-1"}`
+	if requests[0] != want {
+		t.Errorf("request = %s, want %s", requests[0], want)
+	}
+	if req.OrdType != "" {
+		t.Error("caller request should not be modified")
+	}
+
+	_, err = c.PlaceTwapOrder(&TwapOrderRequest{
+		IcebergOrderRequest: IcebergOrderRequest{
+			AlgoOrderRequest: AlgoOrderRequest{InstId: "BTC-USDT", TdMode: Cash, Side: Buy, Sz: "10"},
+			PxVar:            "0.01",
+			SzLimit:          "1",
+			PxLimit:          "40000",
+		},
+		TimeInterval: "10",
+	})
+	if err != nil || !strings.Contains(requests[1], `"ordType":"twap"`) || !strings.Contains(requests[1], `"timeInterval":"10"`) {
+		t.Errorf("request = %s, err = %v", requests[1], err)
+	}
+
+	order, err := c.AlgoOrderDetail("7", "")
+	if err != nil || order.OrdType != Twap || order.PxVar != "0.01" {
+		t.Fatalf("order = %+v, err = %v", order, err)
+	}
+	if want = "GET " + PostOrderAlgo + "?algoId=7 "; requests[2] != want {
+		t.Errorf("request = %s, want %s", requests[2], want)
+	}
+
+	if _, err = c.ListAlgoOrdersPending(&AlgoOrdersRequest{OrdType: Conditional + "," + Oco, InstType: SPOT}); err != nil {
+		t.Fatal(err)
+	}
+	if want = "GET " + OrdersAlgoPendingUrl + "?instType=SPOT&ordType=conditional%2Coco "; requests[3] != want {
+		t.Errorf("request = %s, want %s", requests[3], want)
+	}
+
+	if _, err = c.ListAlgoOrdersHistory(&AlgoOrdersRequest{OrdType: Plan}); err == nil || len(requests) != 4 {
+		t.Errorf("history without state or algoId should fail before sending, err = %v", err)
+	}
+}
+
+func TestCancelAlgoOrders(t *testing.T) {
+	var sizes []int
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		var reqs []*CancelAlgoRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(reqs))
+
+		var items []string
+		for _, req := range reqs {
+			items = append(items, fmt.Sprintf(`{"algoId":"%s","sCode":"0","sMsg":""}`, req.AlgoId))
+		}
+		return stubResponse(http.StatusOK, fmt.Sprintf(`{"code":"0","msg":"","data":[%s]}`, strings.Join(items, ","))), nil
+	})
+	c.SetRateLimiter(NewRateLimiter(RateLimitDisabled))
+
+	var reqs []*CancelAlgoRequest
+	for i := 0; i < 25; i++ {
+		reqs = append(reqs, &CancelAlgoRequest{InstId: "BTC-USDT", AlgoId: fmt.Sprint(i)})
+	}
+
+	results, err := c.CancelAlgoOrders(reqs)
+	if err != nil || fmt.Sprint(sizes) != "[10 10 5]" || len(results) != 25 || results[24].AlgoId != "24" {
+		t.Errorf("sizes = %v, results = %d, err = %v", sizes, len(results), err)
+	}
+}
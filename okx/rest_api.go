@@ -1,6 +1,7 @@
 package okx
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
@@ -8,12 +9,15 @@ import (
 	"fmt"
 	"github.com/hansdq/go-okx/common/utils"
 	"github.com/pkg/errors"
+	"github.com/zeromicro/go-zero/core/logx"
 	"io"
 	"math"
 	"net/http"
 	"net/url"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,8 +27,22 @@ type RestConfig struct {
 	Password  string
 	Simulate  bool // 模拟盘标识
 	Proxy     string
-	Host      string
-	Timeout   int
+	Host      string // rest地址，为空时根据Simulate使用默认地址
+	Timeout   int    // 请求超时时间，单位秒，为0时使用DefaultTimeout
+
+	mu      sync.Mutex
+	client  *http.Client
+	limiter *RateLimiter
+	retry   *RetryPolicy
+	expiry  time.Duration
+
+	// 交易所时间偏移，单位毫秒
+	offset         atomic.Int64
+	syncMu         sync.Mutex
+	syncedAt       time.Time
+	syncTried      time.Time
+	syncInterval   time.Duration
+	syncConfigured bool
 }
 
 type ResponseBean struct {
@@ -47,6 +65,9 @@ type FundingRate struct {
 	NextFundingTime string `json:"nextFundingTime"`
 	SettFundingRate string `json:"settFundingRate"`
 	SettState       string `json:"settState"`
+	Premium         string `json:"premium"`      // 溢价指数
+	InterestRate    string `json:"interestRate"` // 利率
+	ImpactValue     string `json:"impactValue"`  // 深度加权金额
 	Ts              string `json:"ts"`
 }
 
@@ -111,12 +132,19 @@ type Account struct {
 }
 
 type Candles struct {
-	Timestamp int64
-	Open      float64
-	High      float64
-	Low       float64
-	Close     float64
-	Confirm   int64 // k线是否完结 0未完结 1完结
+	Timestamp   int64
+	Open        float64
+	High        float64
+	Low         float64
+	Close       float64
+	Confirm     int64   // k线是否完结 0未完结 1完结
+	OpenPx      Decimal // 开盘价的精确值
+	HighPx      Decimal
+	LowPx       Decimal
+	ClosePx     Decimal
+	Vol         Decimal // 以张为单位的交易量，币币为交易货币
+	VolCcy      Decimal // 以币为单位的交易量，币币为计价货币
+	VolCcyQuote Decimal // 以计价货币为单位的交易量
 }
 
 type AccountConfig struct {
@@ -143,16 +171,32 @@ type AccountConfig struct {
 }
 
 type Book struct {
-	Asks [][]string `json:"asks"`
-	Bids [][]string `json:"bids"`
-	Ts   string     `json:"ts"`
+	Asks      [][]string `json:"asks"`
+	Bids      [][]string `json:"bids"`
+	Ts        string     `json:"ts"`
+	Checksum  int64      `json:"checksum,omitempty"`  // 推送数据的校验和
+	SeqId     int64      `json:"seqId,omitempty"`     // 推送数据的序号
+	PrevSeqId int64      `json:"prevSeqId,omitempty"` // 上一条推送的序号，快照为-1
 }
 
 type BookOrder struct {
 	Price         float64 // 价格
 	Amount        float64 // 数量
-	Value         float64 // 总价
+	Value         float64 // 总价，取整
 	OrderQuantity int64   // 此价格的订单数量
+	Px            Decimal // 价格的精确值
+	Sz            Decimal // 数量的精确值
+	Notional      Decimal // 总价的精确值
+}
+
+// AmendResult 修改订单的结果
+type AmendResult struct {
+	OrdId   string `json:"ordId"`
+	ClOrdId string `json:"clOrdId"`
+	ReqId   string `json:"reqId"`
+	Ts      string `json:"ts"`
+	SCode   string `json:"sCode"`
+	SMsg    string `json:"sMsg"`
 }
 
 type MaxSize struct {
@@ -446,8 +490,56 @@ func InitRestConfig(apiKey, secretKey, password, proxy string, simulate bool) *R
 	}
 }
 
+// SetHttpClient 使用自定义的http.Client发送请求，client会被所有请求复用
+func (c *RestConfig) SetHttpClient(client *http.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.client = client
+}
+
+// SetTransport 使用自定义的RoundTripper发送请求，超时时间仍然遵循Timeout
+func (c *RestConfig) SetTransport(transport http.RoundTripper) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.client = &http.Client{
+		Timeout:   c.timeout(),
+		Transport: transport,
+	}
+}
+
+// SetEndpoint 使用指定的地址发送请求，可以在请求进行中调用
+func (c *RestConfig) SetEndpoint(e Endpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Host = e.Rest
+	c.Simulate = e.Simulate
+}
+
+// SetRegion 使用指定区域的地址发送请求
+func (c *RestConfig) SetRegion(region string) error {
+	e, err := GetEndpoint(region)
+	if err != nil {
+		return err
+	}
+
+	c.SetEndpoint(e)
+	return nil
+}
+
+// SetRateLimiter 设置本地限速器，多个使用同一账户的RestConfig可以共享同一个限速器
+func (c *RestConfig) SetRateLimiter(limiter *RateLimiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.limiter = limiter
+}
+
 func (c *RestConfig) CheckLocalTime() error {
-	t, err := c.GetTime()
+	return c.CheckLocalTimeWithContext(context.Background())
+}
+
+// CheckLocalTimeWithContext 同CheckLocalTime，使用ctx控制请求的取消与超时
+func (c *RestConfig) CheckLocalTimeWithContext(ctx context.Context) error {
+	t, err := c.GetTimeWithContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -463,8 +555,13 @@ func (c *RestConfig) CheckLocalTime() error {
 
 // GetTime 获取交易所时间
 func (c *RestConfig) GetTime() (*SystemTime, error) {
+	return c.GetTimeWithContext(context.Background())
+}
+
+// GetTimeWithContext 同GetTime，使用ctx控制请求的取消与超时
+func (c *RestConfig) GetTimeWithContext(ctx context.Context) (*SystemTime, error) {
 	var systemTimes []*SystemTime
-	_, err := c.request(nil, &systemTimes, http.MethodGet, TimeUrl, "", true)
+	_, err := c.request(ctx, nil, &systemTimes, http.MethodGet, TimeUrl, "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -474,6 +571,11 @@ func (c *RestConfig) GetTime() (*SystemTime, error) {
 
 // Positions 获取持仓信息
 func (c *RestConfig) Positions(instType, instId, posId string) ([]*Position, error) {
+	return c.PositionsWithContext(context.Background(), instType, instId, posId)
+}
+
+// PositionsWithContext 同Positions，使用ctx控制请求的取消与超时
+func (c *RestConfig) PositionsWithContext(ctx context.Context, instType, instId, posId string) ([]*Position, error) {
 	data := url.Values{
 		"instType": {instType},
 		"instId":   {instId},
@@ -481,7 +583,7 @@ func (c *RestConfig) Positions(instType, instId, posId string) ([]*Position, err
 	}
 
 	var positions []*Position
-	_, err := c.request(nil, &positions, http.MethodGet, fmt.Sprintf("%s?%s", PositionsUrl, data.Encode()), "", false)
+	_, err := c.request(ctx, nil, &positions, http.MethodGet, fmt.Sprintf("%s?%s", PositionsUrl, data.Encode()), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -490,20 +592,51 @@ func (c *RestConfig) Positions(instType, instId, posId string) ([]*Position, err
 }
 
 // PositionsHistory 获取历史持仓信息
+//
+// Deprecated: 使用ListPositionsHistory
 func (c *RestConfig) PositionsHistory(instType, instId, posId, mgnMode, tp, after, before, limit string) ([]*Position, error) {
-	data := url.Values{
-		"instType": {instType},
-		"instId":   {instId},
-		"posId":    {posId},
-		"mgnMode":  {mgnMode},
-		"type":     {tp},
-		"after":    {after},
-		"before":   {before},
-		"limit":    {limit},
+	return c.PositionsHistoryWithContext(context.Background(), instType, instId, posId, mgnMode, tp, after, before, limit)
+}
+
+// PositionsHistoryWithContext 同PositionsHistory，使用ctx控制请求的取消与超时
+//
+// Deprecated: 使用ListPositionsHistoryWithContext
+func (c *RestConfig) PositionsHistoryWithContext(ctx context.Context, instType, instId, posId, mgnMode, tp, after, before, limit string) ([]*Position, error) {
+	req := &PositionsHistoryRequest{
+		InstType: instType,
+		InstId:   instId,
+		PosId:    posId,
+		MgnMode:  mgnMode,
+		Type:     tp,
+	}
+
+	var err error
+	if req.After, err = parseMilli("after", after); err != nil {
+		return nil, err
+	}
+	if req.Before, err = parseMilli("before", before); err != nil {
+		return nil, err
+	}
+	if req.Limit, err = atoi("limit", limit); err != nil {
+		return nil, err
+	}
+
+	return c.ListPositionsHistoryWithContext(ctx, req)
+}
+
+// ListPositionsHistory 获取历史持仓信息
+func (c *RestConfig) ListPositionsHistory(req *PositionsHistoryRequest) ([]*Position, error) {
+	return c.ListPositionsHistoryWithContext(context.Background(), req)
+}
+
+// ListPositionsHistoryWithContext 同ListPositionsHistory，使用ctx控制请求的取消与超时
+func (c *RestConfig) ListPositionsHistoryWithContext(ctx context.Context, req *PositionsHistoryRequest) ([]*Position, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
 
 	var positions []*Position
-	_, err := c.request(nil, &positions, http.MethodGet, fmt.Sprintf("%s?%s", PositionsHistoryUrl, data.Encode()), "", false)
+	_, err := c.request(ctx, nil, &positions, http.MethodGet, fmt.Sprintf("%s?%s", PositionsHistoryUrl, encodeQuery(req)), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -513,6 +646,11 @@ func (c *RestConfig) PositionsHistory(instType, instId, posId, mgnMode, tp, afte
 
 // ClosePosition 市价全平
 func (c *RestConfig) ClosePosition(instId string, posSide string, mgnMode string, ccy string, autoCxl bool) error {
+	return c.ClosePositionWithContext(context.Background(), instId, posSide, mgnMode, ccy, autoCxl)
+}
+
+// ClosePositionWithContext 同ClosePosition，使用ctx控制请求的取消与超时
+func (c *RestConfig) ClosePositionWithContext(ctx context.Context, instId string, posSide string, mgnMode string, ccy string, autoCxl bool) error {
 	data := Params{
 		"instId":  instId,
 		"posSide": posSide,
@@ -521,7 +659,7 @@ func (c *RestConfig) ClosePosition(instId string, posSide string, mgnMode string
 		"autoCxl": autoCxl,
 	}
 
-	_, err := c.request(data, nil, http.MethodPost, ClosePositionUrl, "", false)
+	_, err := c.request(ctx, data, nil, http.MethodPost, ClosePositionUrl, "", false)
 	if err != nil {
 		return err
 	}
@@ -531,8 +669,13 @@ func (c *RestConfig) ClosePosition(instId string, posSide string, mgnMode string
 
 // AccountConfig 获取账户配置
 func (c *RestConfig) AccountConfig() (*AccountConfig, error) {
+	return c.AccountConfigWithContext(context.Background())
+}
+
+// AccountConfigWithContext 同AccountConfig，使用ctx控制请求的取消与超时
+func (c *RestConfig) AccountConfigWithContext(ctx context.Context) (*AccountConfig, error) {
 	var accountConfig []*AccountConfig
-	_, err := c.request(nil, &accountConfig, http.MethodGet, AccountConfigUrl, "", false)
+	_, err := c.request(ctx, nil, &accountConfig, http.MethodGet, AccountConfigUrl, "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -542,12 +685,17 @@ func (c *RestConfig) AccountConfig() (*AccountConfig, error) {
 
 // Ticker 产品当前行情数据
 func (c *RestConfig) Ticker(instId string) (*Ticker, error) {
+	return c.TickerWithContext(context.Background(), instId)
+}
+
+// TickerWithContext 同Ticker，使用ctx控制请求的取消与超时
+func (c *RestConfig) TickerWithContext(ctx context.Context, instId string) (*Ticker, error) {
 	data := url.Values{
 		"instId": {instId},
 	}
 
 	var tickers []*Ticker
-	_, err := c.request(nil, &tickers, http.MethodGet, fmt.Sprintf("%s?%s", TickerUrl, data.Encode()), "", true)
+	_, err := c.request(ctx, nil, &tickers, http.MethodGet, fmt.Sprintf("%s?%s", TickerUrl, data.Encode()), "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -557,12 +705,17 @@ func (c *RestConfig) Ticker(instId string) (*Ticker, error) {
 
 // Ticker 产品当前行情数据
 func (c *RestConfig) Tickers(instType string) ([]*Ticker, error) {
+	return c.TickersWithContext(context.Background(), instType)
+}
+
+// TickersWithContext 同Tickers，使用ctx控制请求的取消与超时
+func (c *RestConfig) TickersWithContext(ctx context.Context, instType string) ([]*Ticker, error) {
 	data := url.Values{
 		"instType": {instType},
 	}
 
 	var tickers []*Ticker
-	_, err := c.request(nil, &tickers, http.MethodGet, fmt.Sprintf("%s?%s", TickersUrl, data.Encode()), "", true)
+	_, err := c.request(ctx, nil, &tickers, http.MethodGet, fmt.Sprintf("%s?%s", TickersUrl, data.Encode()), "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -574,6 +727,11 @@ func (c *RestConfig) Tickers(instType string) ([]*Ticker, error) {
 // 币币：返回最大可买的交易币数量和最大可卖的计价币数量，例如：BTC-USDT，返回的是BTC的最大可买数量和USDT的最大可卖数量
 // 合约：返回最大可开多的合约张数和最大可开空的合约张数
 func (c *RestConfig) MaxSize(instId, tdMode, ccy, px, leverages string, unSpotOffset bool) (*MaxSize, error) {
+	return c.MaxSizeWithContext(context.Background(), instId, tdMode, ccy, px, leverages, unSpotOffset)
+}
+
+// MaxSizeWithContext 同MaxSize，使用ctx控制请求的取消与超时
+func (c *RestConfig) MaxSizeWithContext(ctx context.Context, instId, tdMode, ccy, px, leverages string, unSpotOffset bool) (*MaxSize, error) {
 	data := url.Values{
 		"instId":       {instId},
 		"tdMode":       {tdMode},
@@ -584,7 +742,7 @@ func (c *RestConfig) MaxSize(instId, tdMode, ccy, px, leverages string, unSpotOf
 	}
 
 	var maxSize []*MaxSize
-	_, err := c.request(nil, &maxSize, http.MethodGet, fmt.Sprintf("%s?%s", MaxSizeUrl, data.Encode()), "", false)
+	_, err := c.request(ctx, nil, &maxSize, http.MethodGet, fmt.Sprintf("%s?%s", MaxSizeUrl, data.Encode()), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -594,6 +752,11 @@ func (c *RestConfig) MaxSize(instId, tdMode, ccy, px, leverages string, unSpotOf
 
 // Candles 近期k线数据
 func (c *RestConfig) Candles(instId, bar, after, before, limit string) ([]*Candles, error) {
+	return c.CandlesWithContext(context.Background(), instId, bar, after, before, limit)
+}
+
+// CandlesWithContext 同Candles，使用ctx控制请求的取消与超时
+func (c *RestConfig) CandlesWithContext(ctx context.Context, instId, bar, after, before, limit string) ([]*Candles, error) {
 	data := url.Values{
 		"instId": {instId},
 		"bar":    {bar},
@@ -603,28 +766,21 @@ func (c *RestConfig) Candles(instId, bar, after, before, limit string) ([]*Candl
 	}
 
 	var candles [][]string
-	_, err := c.request(nil, &candles, http.MethodGet, fmt.Sprintf("%s?%s", CandlesUrl, data.Encode()), "", true)
+	_, err := c.request(ctx, nil, &candles, http.MethodGet, fmt.Sprintf("%s?%s", CandlesUrl, data.Encode()), "", true)
 	if err != nil {
 		return nil, err
 	}
 
-	var ret []*Candles
-	for _, item := range candles {
-		ret = append(ret, &Candles{
-			Timestamp: utils.MustParseInt64(item[0]),
-			Open:      utils.MustParseFloat64(item[1]),
-			High:      utils.MustParseFloat64(item[2]),
-			Low:       utils.MustParseFloat64(item[3]),
-			Close:     utils.MustParseFloat64(item[4]),
-			Confirm:   utils.MustParseInt64(item[5]),
-		})
-	}
-
-	return ret, nil
+	return parseCandles(candles)
 }
 
 // HistoryCandles 历史k线数据
 func (c *RestConfig) HistoryCandles(instId, bar, after, before, limit string) ([]*Candles, error) {
+	return c.HistoryCandlesWithContext(context.Background(), instId, bar, after, before, limit)
+}
+
+// HistoryCandlesWithContext 同HistoryCandles，使用ctx控制请求的取消与超时
+func (c *RestConfig) HistoryCandlesWithContext(ctx context.Context, instId, bar, after, before, limit string) ([]*Candles, error) {
 	data := url.Values{
 		"instId": {instId},
 		"bar":    {bar},
@@ -634,34 +790,27 @@ func (c *RestConfig) HistoryCandles(instId, bar, after, before, limit string) ([
 	}
 
 	var candles [][]string
-	_, err := c.request(nil, &candles, http.MethodGet, fmt.Sprintf("%s?%s", HisCandlesUrl, data.Encode()), "", true)
+	_, err := c.request(ctx, nil, &candles, http.MethodGet, fmt.Sprintf("%s?%s", HisCandlesUrl, data.Encode()), "", true)
 	if err != nil {
 		return nil, err
 	}
 
-	var ret []*Candles
-	for _, item := range candles {
-		ret = append(ret, &Candles{
-			Timestamp: utils.MustParseInt64(item[0]),
-			Open:      utils.MustParseFloat64(item[1]),
-			High:      utils.MustParseFloat64(item[2]),
-			Low:       utils.MustParseFloat64(item[3]),
-			Close:     utils.MustParseFloat64(item[4]),
-			Confirm:   utils.MustParseInt64(item[5]),
-		})
-	}
-
-	return ret, nil
+	return parseCandles(candles)
 }
 
 // Balance 指定币种账户余额
 func (c *RestConfig) Balance(ccy []string) (*Account, error) {
+	return c.BalanceWithContext(context.Background(), ccy)
+}
+
+// BalanceWithContext 同Balance，使用ctx控制请求的取消与超时
+func (c *RestConfig) BalanceWithContext(ctx context.Context, ccy []string) (*Account, error) {
 	data := url.Values{
 		"ccy": {strings.Join(ccy, ",")},
 	}
 
 	var account []*Account
-	_, err := c.request(nil, &account, http.MethodGet, fmt.Sprintf("%s?%s", BalanceUrl, data.Encode()), "", false)
+	_, err := c.request(ctx, nil, &account, http.MethodGet, fmt.Sprintf("%s?%s", BalanceUrl, data.Encode()), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -671,12 +820,17 @@ func (c *RestConfig) Balance(ccy []string) (*Account, error) {
 
 // FundingRate 资金费率
 func (c *RestConfig) FundingRate(instId string) (*FundingRate, error) {
+	return c.FundingRateWithContext(context.Background(), instId)
+}
+
+// FundingRateWithContext 同FundingRate，使用ctx控制请求的取消与超时
+func (c *RestConfig) FundingRateWithContext(ctx context.Context, instId string) (*FundingRate, error) {
 	data := url.Values{
 		"instId": {instId},
 	}
 
 	var fundingRate []*FundingRate
-	_, err := c.request(nil, &fundingRate, http.MethodGet, fmt.Sprintf("%s?%s", FundingRateUrl, data.Encode()), "", true)
+	_, err := c.request(ctx, nil, &fundingRate, http.MethodGet, fmt.Sprintf("%s?%s", FundingRateUrl, data.Encode()), "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -686,31 +840,61 @@ func (c *RestConfig) FundingRate(instId string) (*FundingRate, error) {
 
 // SpotInstruments 产品列表-币币
 func (c *RestConfig) SpotInstruments(uly, instFamily, instId string) ([]*Instrument, error) {
-	return c.Instruments(SPOT, uly, instFamily, instId)
+	return c.SpotInstrumentsWithContext(context.Background(), uly, instFamily, instId)
+}
+
+// SpotInstrumentsWithContext 同SpotInstruments，使用ctx控制请求的取消与超时
+func (c *RestConfig) SpotInstrumentsWithContext(ctx context.Context, uly, instFamily, instId string) ([]*Instrument, error) {
+	return c.InstrumentsWithContext(ctx, SPOT, uly, instFamily, instId)
 }
 
 // MarginInstruments 产品列表-币币杠杆
 func (c *RestConfig) MarginInstruments(uly, instFamily, instId string) ([]*Instrument, error) {
-	return c.Instruments(MARGIN, uly, instFamily, instId)
+	return c.MarginInstrumentsWithContext(context.Background(), uly, instFamily, instId)
+}
+
+// MarginInstrumentsWithContext 同MarginInstruments，使用ctx控制请求的取消与超时
+func (c *RestConfig) MarginInstrumentsWithContext(ctx context.Context, uly, instFamily, instId string) ([]*Instrument, error) {
+	return c.InstrumentsWithContext(ctx, MARGIN, uly, instFamily, instId)
 }
 
 // SwapInstruments 产品列表-永续合约
 func (c *RestConfig) SwapInstruments(uly, instFamily, instId string) ([]*Instrument, error) {
-	return c.Instruments(SWAP, uly, instFamily, instId)
+	return c.SwapInstrumentsWithContext(context.Background(), uly, instFamily, instId)
+}
+
+// SwapInstrumentsWithContext 同SwapInstruments，使用ctx控制请求的取消与超时
+func (c *RestConfig) SwapInstrumentsWithContext(ctx context.Context, uly, instFamily, instId string) ([]*Instrument, error) {
+	return c.InstrumentsWithContext(ctx, SWAP, uly, instFamily, instId)
 }
 
 // FuturesInstruments 产品列表-交割合约
 func (c *RestConfig) FuturesInstruments(uly, instFamily, instId string) ([]*Instrument, error) {
-	return c.Instruments(FUTURES, uly, instFamily, instId)
+	return c.FuturesInstrumentsWithContext(context.Background(), uly, instFamily, instId)
+}
+
+// FuturesInstrumentsWithContext 同FuturesInstruments，使用ctx控制请求的取消与超时
+func (c *RestConfig) FuturesInstrumentsWithContext(ctx context.Context, uly, instFamily, instId string) ([]*Instrument, error) {
+	return c.InstrumentsWithContext(ctx, FUTURES, uly, instFamily, instId)
 }
 
 // OptionInstruments 产品列表-期权
 func (c *RestConfig) OptionInstruments(uly, instFamily, instId string) ([]*Instrument, error) {
-	return c.Instruments(OPTION, uly, instFamily, instId)
+	return c.OptionInstrumentsWithContext(context.Background(), uly, instFamily, instId)
+}
+
+// OptionInstrumentsWithContext 同OptionInstruments，使用ctx控制请求的取消与超时
+func (c *RestConfig) OptionInstrumentsWithContext(ctx context.Context, uly, instFamily, instId string) ([]*Instrument, error) {
+	return c.InstrumentsWithContext(ctx, OPTION, uly, instFamily, instId)
 }
 
 // Instruments 产品列表
 func (c *RestConfig) Instruments(instType, uly, instFamily, instId string) ([]*Instrument, error) {
+	return c.InstrumentsWithContext(context.Background(), instType, uly, instFamily, instId)
+}
+
+// InstrumentsWithContext 同Instruments，使用ctx控制请求的取消与超时
+func (c *RestConfig) InstrumentsWithContext(ctx context.Context, instType, uly, instFamily, instId string) ([]*Instrument, error) {
 	data := url.Values{
 		"instType":   {instType},
 		"uly":        {uly},
@@ -719,7 +903,7 @@ func (c *RestConfig) Instruments(instType, uly, instFamily, instId string) ([]*I
 	}
 
 	var instruments []*Instrument
-	_, err := c.request(nil, &instruments, http.MethodGet, fmt.Sprintf("%s?%s", InstrumentsUrl, data.Encode()), "", true)
+	_, err := c.request(ctx, nil, &instruments, http.MethodGet, fmt.Sprintf("%s?%s", InstrumentsUrl, data.Encode()), "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -733,12 +917,17 @@ func (c *RestConfig) Instruments(instType, uly, instFamily, instId string) ([]*I
 
 // AssetBalances 资金账户余额
 func (c *RestConfig) AssetBalances(ccy []string) ([]*Balance, error) {
+	return c.AssetBalancesWithContext(context.Background(), ccy)
+}
+
+// AssetBalancesWithContext 同AssetBalances，使用ctx控制请求的取消与超时
+func (c *RestConfig) AssetBalancesWithContext(ctx context.Context, ccy []string) ([]*Balance, error) {
 	data := url.Values{
 		"ccy": {strings.Join(ccy, ",")},
 	}
 
 	var balances []*Balance
-	_, err := c.request(nil, &balances, http.MethodGet, fmt.Sprintf("%s?%s", AssetBalancesUrl, data.Encode()), "", false)
+	_, err := c.request(ctx, nil, &balances, http.MethodGet, fmt.Sprintf("%s?%s", AssetBalancesUrl, data.Encode()), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -748,12 +937,17 @@ func (c *RestConfig) AssetBalances(ccy []string) ([]*Balance, error) {
 
 // AssetValuation 资产估值
 func (c *RestConfig) AssetValuation(ccy string) (*Asset, error) {
+	return c.AssetValuationWithContext(context.Background(), ccy)
+}
+
+// AssetValuationWithContext 同AssetValuation，使用ctx控制请求的取消与超时
+func (c *RestConfig) AssetValuationWithContext(ctx context.Context, ccy string) (*Asset, error) {
 	data := url.Values{
 		"ccy": {ccy},
 	}
 
 	var assets []*Asset
-	_, err := c.request(nil, &assets, http.MethodGet, fmt.Sprintf("%s?%s", AssetValuationUrl, data.Encode()), "", false)
+	_, err := c.request(ctx, nil, &assets, http.MethodGet, fmt.Sprintf("%s?%s", AssetValuationUrl, data.Encode()), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -764,51 +958,107 @@ func (c *RestConfig) AssetValuation(ccy string) (*Asset, error) {
 // MarginMarketBuyOrder 币币杠杆-市价买入
 // 注意：市价买入时买入所使用的货币和数量都是ccy
 func (c *RestConfig) MarginMarketBuyOrder(instId, tdMode, ccy, sz string) (*Order, error) {
-	return c.MakeOrder(instId, tdMode, ccy, "", Buy, Market, "", sz, false, "", "", false, nil)
+	return c.MarginMarketBuyOrderWithContext(context.Background(), instId, tdMode, ccy, sz)
+}
+
+// MarginMarketBuyOrderWithContext 同MarginMarketBuyOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) MarginMarketBuyOrderWithContext(ctx context.Context, instId, tdMode, ccy, sz string) (*Order, error) {
+	return c.PlaceOrderWithContext(ctx, &PlaceOrderRequest{InstId: instId, TdMode: tdMode, Ccy: ccy, Side: Buy, OrdType: Market, Sz: sz})
 }
 
 // MarginMarketSellOrder 币币杠杆-市价卖出
 // 注意：市价卖出时卖出的货币和数量都是instId
 func (c *RestConfig) MarginMarketSellOrder(instId, tdMode, ccy, sz string) (*Order, error) {
-	return c.MakeOrder(instId, tdMode, ccy, "", Sell, Market, "", sz, false, "", "", false, nil)
+	return c.MarginMarketSellOrderWithContext(context.Background(), instId, tdMode, ccy, sz)
+}
+
+// MarginMarketSellOrderWithContext 同MarginMarketSellOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) MarginMarketSellOrderWithContext(ctx context.Context, instId, tdMode, ccy, sz string) (*Order, error) {
+	return c.PlaceOrderWithContext(ctx, &PlaceOrderRequest{InstId: instId, TdMode: tdMode, Ccy: ccy, Side: Sell, OrdType: Market, Sz: sz})
 }
 
 // SpotMarketBuyOrder 币币-市价买入
 func (c *RestConfig) SpotMarketBuyOrder(instId, sz, tgtCcy string) (*Order, error) {
-	return c.MakeOrder(instId, Cash, "", "", Buy, Market, "", sz, false, "", tgtCcy, false, nil)
+	return c.SpotMarketBuyOrderWithContext(context.Background(), instId, sz, tgtCcy)
+}
+
+// SpotMarketBuyOrderWithContext 同SpotMarketBuyOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) SpotMarketBuyOrderWithContext(ctx context.Context, instId, sz, tgtCcy string) (*Order, error) {
+	return c.PlaceOrderWithContext(ctx, &PlaceOrderRequest{InstId: instId, TdMode: Cash, Side: Buy, OrdType: Market, Sz: sz, TgtCcy: tgtCcy})
 }
 
 // SpotMarketSellOrder 币币-市价卖出
 func (c *RestConfig) SpotMarketSellOrder(instId, sz, tgtCcy string) (*Order, error) {
-	return c.MakeOrder(instId, Cash, "", "", Sell, Market, "", sz, false, "", tgtCcy, false, nil)
+	return c.SpotMarketSellOrderWithContext(context.Background(), instId, sz, tgtCcy)
+}
+
+// SpotMarketSellOrderWithContext 同SpotMarketSellOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) SpotMarketSellOrderWithContext(ctx context.Context, instId, sz, tgtCcy string) (*Order, error) {
+	return c.PlaceOrderWithContext(ctx, &PlaceOrderRequest{InstId: instId, TdMode: Cash, Side: Sell, OrdType: Market, Sz: sz, TgtCcy: tgtCcy})
 }
 
 // SwapMarketShortOrder 合约市价做空
 func (c *RestConfig) SwapMarketShortOrder(instId, tdMode, sz string, triggers []*Trigger) (*Order, error) {
-	return c.MakeOrder(instId, tdMode, "", "", Sell, Market, "", sz, false, MakeShort, "", false, triggers)
+	return c.SwapMarketShortOrderWithContext(context.Background(), instId, tdMode, sz, triggers)
+}
+
+// SwapMarketShortOrderWithContext 同SwapMarketShortOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) SwapMarketShortOrderWithContext(ctx context.Context, instId, tdMode, sz string, triggers []*Trigger) (*Order, error) {
+	return c.PlaceOrderWithContext(ctx, &PlaceOrderRequest{InstId: instId, TdMode: tdMode, Side: Sell, PosSide: MakeShort, OrdType: Market, Sz: sz, AttachAlgoOrds: triggers})
 }
 
 // SwapMarketLongOrder 合约市价做多
 func (c *RestConfig) SwapMarketLongOrder(instId, tdMode, sz string, triggers []*Trigger) (*Order, error) {
-	return c.MakeOrder(instId, tdMode, "", "", Buy, Market, "", sz, false, MakeLong, "", false, triggers)
+	return c.SwapMarketLongOrderWithContext(context.Background(), instId, tdMode, sz, triggers)
+}
+
+// SwapMarketLongOrderWithContext 同SwapMarketLongOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) SwapMarketLongOrderWithContext(ctx context.Context, instId, tdMode, sz string, triggers []*Trigger) (*Order, error) {
+	return c.PlaceOrderWithContext(ctx, &PlaceOrderRequest{InstId: instId, TdMode: tdMode, Side: Buy, PosSide: MakeLong, OrdType: Market, Sz: sz, AttachAlgoOrds: triggers})
 }
 
 // OrdersPending 获取未成交订单列表
+//
+// Deprecated: 使用ListOrdersPending
 func (c *RestConfig) OrdersPending(instType, uly, instFamily, instId, ordType, state, after, before, limit string) ([]*Order, error) {
-	data := url.Values{
-		"instType":   {instType},
-		"uly":        {uly},
-		"instFamily": {instFamily},
-		"instId":     {instId},
-		"ordType":    {ordType},
-		"state":      {state},
-		"after":      {after},
-		"before":     {before},
-		"limit":      {limit},
+	return c.OrdersPendingWithContext(context.Background(), instType, uly, instFamily, instId, ordType, state, after, before, limit)
+}
+
+// OrdersPendingWithContext 同OrdersPending，使用ctx控制请求的取消与超时
+//
+// Deprecated: 使用ListOrdersPendingWithContext
+func (c *RestConfig) OrdersPendingWithContext(ctx context.Context, instType, uly, instFamily, instId, ordType, state, after, before, limit string) ([]*Order, error) {
+	n, err := atoi("limit", limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ListOrdersPendingWithContext(ctx, &OrdersPendingRequest{
+		InstType:   instType,
+		Uly:        uly,
+		InstFamily: instFamily,
+		InstId:     instId,
+		OrdType:    ordType,
+		State:      state,
+		After:      after,
+		Before:     before,
+		Limit:      n,
+	})
+}
+
+// ListOrdersPending 获取未成交订单列表
+func (c *RestConfig) ListOrdersPending(req *OrdersPendingRequest) ([]*Order, error) {
+	return c.ListOrdersPendingWithContext(context.Background(), req)
+}
+
+// ListOrdersPendingWithContext 同ListOrdersPending，使用ctx控制请求的取消与超时
+func (c *RestConfig) ListOrdersPendingWithContext(ctx context.Context, req *OrdersPendingRequest) ([]*Order, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
 
 	var orders []*Order
-	_, err := c.request(nil, &orders, http.MethodGet, fmt.Sprintf("%s?%s", OrdersPendingUrl, data.Encode()), "", false)
+	_, err := c.request(ctx, nil, &orders, http.MethodGet, fmt.Sprintf("%s?%s", OrdersPendingUrl, encodeQuery(req)), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -816,36 +1066,59 @@ func (c *RestConfig) OrdersPending(instType, uly, instFamily, instId, ordType, s
 	return orders, nil
 }
 
+// BatchOrders 批量下单，部分成功时同时返回结果和*APIError，失败项见APIError.Items
 func (c *RestConfig) BatchOrders(data []*Order) ([]*Order, error) {
-	var ret []*Order
-	_, err := c.request(data, &ret, http.MethodPost, BatchOrdersUrl, "", false)
-	if err != nil {
-		return nil, err
-	}
+	return c.BatchOrdersWithContext(context.Background(), data)
+}
 
-	return ret, nil
+// BatchOrdersWithContext 同BatchOrders，使用ctx控制请求的取消与超时
+func (c *RestConfig) BatchOrdersWithContext(ctx context.Context, data []*Order) ([]*Order, error) {
+	var ret []*Order
+	_, err := c.request(ctx, data, &ret, http.MethodPost, BatchOrdersUrl, "", false)
+	return ret, err
 }
 
 // MakeOrder 下单
+//
+// Deprecated: 使用PlaceOrder
 func (c *RestConfig) MakeOrder(instId string, tdMode string, ccy string, clOrdId string, side string, ordType string, px string, sz string, reduceOnly bool, posSide string, tgtCcy string, banAmend bool, triggers []*Trigger) (*Order, error) {
-	data := Params{
-		"instId":         instId,
-		"tdMode":         tdMode,
-		"ccy":            ccy,
-		"clOrdId":        clOrdId,
-		"side":           side,
-		"ordType":        ordType,
-		"px":             px,
-		"sz":             sz,
-		"reduceOnly":     reduceOnly,
-		"posSide":        posSide,
-		"tgtCcy":         tgtCcy,
-		"banAmend":       banAmend,
-		"attachAlgoOrds": triggers,
+	return c.MakeOrderWithContext(context.Background(), instId, tdMode, ccy, clOrdId, side, ordType, px, sz, reduceOnly, posSide, tgtCcy, banAmend, triggers)
+}
+
+// MakeOrderWithContext 同MakeOrder，使用ctx控制请求的取消与超时
+//
+// Deprecated: 使用PlaceOrderWithContext
+func (c *RestConfig) MakeOrderWithContext(ctx context.Context, instId string, tdMode string, ccy string, clOrdId string, side string, ordType string, px string, sz string, reduceOnly bool, posSide string, tgtCcy string, banAmend bool, triggers []*Trigger) (*Order, error) {
+	return c.PlaceOrderWithContext(ctx, &PlaceOrderRequest{
+		InstId:         instId,
+		TdMode:         tdMode,
+		Ccy:            ccy,
+		ClOrdId:        clOrdId,
+		Side:           side,
+		OrdType:        ordType,
+		Px:             px,
+		Sz:             sz,
+		ReduceOnly:     reduceOnly,
+		PosSide:        posSide,
+		TgtCcy:         tgtCcy,
+		BanAmend:       banAmend,
+		AttachAlgoOrds: triggers,
+	})
+}
+
+// PlaceOrder 下单
+func (c *RestConfig) PlaceOrder(req *PlaceOrderRequest) (*Order, error) {
+	return c.PlaceOrderWithContext(context.Background(), req)
+}
+
+// PlaceOrderWithContext 同PlaceOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) PlaceOrderWithContext(ctx context.Context, req *PlaceOrderRequest) (*Order, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
 
 	var order []*Order
-	_, err := c.request(data, &order, http.MethodPost, OrderUrl, "", false)
+	_, err := c.request(ctx, req, &order, http.MethodPost, OrderUrl, "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -855,6 +1128,11 @@ func (c *RestConfig) MakeOrder(instId string, tdMode string, ccy string, clOrdId
 
 // CheckOrder 查询订单
 func (c *RestConfig) CheckOrder(instId, ordId, clOrdId string) (*Order, error) {
+	return c.CheckOrderWithContext(context.Background(), instId, ordId, clOrdId)
+}
+
+// CheckOrderWithContext 同CheckOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) CheckOrderWithContext(ctx context.Context, instId, ordId, clOrdId string) (*Order, error) {
 	data := url.Values{
 		"instId":  {instId},
 		"ordId":   {ordId},
@@ -862,7 +1140,7 @@ func (c *RestConfig) CheckOrder(instId, ordId, clOrdId string) (*Order, error) {
 	}
 
 	var orders []*Order
-	_, err := c.request(nil, &orders, http.MethodGet, fmt.Sprintf("%s?%s", OrderUrl, data.Encode()), "", false)
+	_, err := c.request(ctx, nil, &orders, http.MethodGet, fmt.Sprintf("%s?%s", OrderUrl, data.Encode()), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -872,6 +1150,11 @@ func (c *RestConfig) CheckOrder(instId, ordId, clOrdId string) (*Order, error) {
 
 // CancelOrder 撤单
 func (c *RestConfig) CancelOrder(instId, ordId, clOrdId string) (*Order, error) {
+	return c.CancelOrderWithContext(context.Background(), instId, ordId, clOrdId)
+}
+
+// CancelOrderWithContext 同CancelOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) CancelOrderWithContext(ctx context.Context, instId, ordId, clOrdId string) (*Order, error) {
 	data := Params{
 		"instId":  instId,
 		"ordId":   ordId,
@@ -879,7 +1162,7 @@ func (c *RestConfig) CancelOrder(instId, ordId, clOrdId string) (*Order, error)
 	}
 
 	var orders []*Order
-	_, err := c.request(data, &orders, http.MethodPost, CancelOrderUrl, "", false)
+	_, err := c.request(ctx, data, &orders, http.MethodPost, CancelOrderUrl, "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -887,34 +1170,86 @@ func (c *RestConfig) CancelOrder(instId, ordId, clOrdId string) (*Order, error)
 	return orders[0], nil
 }
 
+// AmendOrder 修改未完成订单的价格、数量或附带的止盈止损，不会失去排队位置
+func (c *RestConfig) AmendOrder(req *AmendOrderRequest) (*AmendResult, error) {
+	return c.AmendOrderWithContext(context.Background(), req)
+}
+
+// AmendOrderWithContext 同AmendOrder，使用ctx控制请求的取消与超时
+func (c *RestConfig) AmendOrderWithContext(ctx context.Context, req *AmendOrderRequest) (*AmendResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var results []*AmendResult
+	_, err := c.request(ctx, req, &results, http.MethodPost, AmendOrderUrl, "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	return results[0], nil
+}
+
+// BatchAmendOrders 批量修改订单，超过20个时分多次请求
+// 部分失败时同时返回结果和*APIError，APIError.Items中的Index为在reqs中的位置，中途请求整体失败时返回*BatchError
+func (c *RestConfig) BatchAmendOrders(reqs []*AmendOrderRequest) ([]*AmendResult, error) {
+	return c.BatchAmendOrdersWithContext(context.Background(), reqs)
+}
+
+// BatchAmendOrdersWithContext 同BatchAmendOrders，使用ctx控制请求的取消与超时
+func (c *RestConfig) BatchAmendOrdersWithContext(ctx context.Context, reqs []*AmendOrderRequest) ([]*AmendResult, error) {
+	for _, req := range reqs {
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return requestChunked[*AmendOrderRequest, *AmendResult](ctx, c, AmendBatchOrdersUrl, reqs, maxBatchSize)
+}
+
 // SetPosMode 设置持仓模式
 func (c *RestConfig) SetPosMode(mode string) error {
+	return c.SetPosModeWithContext(context.Background(), mode)
+}
+
+// SetPosModeWithContext 同SetPosMode，使用ctx控制请求的取消与超时
+func (c *RestConfig) SetPosModeWithContext(ctx context.Context, mode string) error {
 	data := Params{"posMode": mode}
-	_, err := c.request(data, nil, http.MethodPost, SetPosModeUrl, "", false)
+	_, err := c.request(ctx, data, nil, http.MethodPost, SetPosModeUrl, "", false)
 	return err
 }
 
 // SetLeverage 设置杠杆倍数
 func (c *RestConfig) SetLeverage(instId string, lever string, mgnMode string) error {
+	return c.SetLeverageWithContext(context.Background(), instId, lever, mgnMode)
+}
+
+// SetLeverageWithContext 同SetLeverage，使用ctx控制请求的取消与超时
+func (c *RestConfig) SetLeverageWithContext(ctx context.Context, instId string, lever string, mgnMode string) error {
 	data := Params{
 		"instId":  instId,
 		"lever":   lever,
 		"mgnMode": mgnMode,
 	}
 
-	_, err := c.request(data, nil, http.MethodPost, SetLeverageUrl, "", false)
+	_, err := c.request(ctx, data, nil, http.MethodPost, SetLeverageUrl, "", false)
 	return err
 }
 
 // Books 获取产品深度数据
 func (c *RestConfig) Books(instId, sz string) (*Book, error) {
+	return c.BooksWithContext(context.Background(), instId, sz)
+}
+
+// BooksWithContext 同Books，使用ctx控制请求的取消与超时
+func (c *RestConfig) BooksWithContext(ctx context.Context, instId, sz string) (*Book, error) {
 	data := url.Values{
 		"instId": {instId},
 		"sz":     {sz},
 	}
 
 	var books []*Book
-	_, err := c.request(nil, &books, http.MethodGet, fmt.Sprintf("%s?%s", BooksUrl, data.Encode()), "", true)
+	_, err := c.request(ctx, nil, &books, http.MethodGet, fmt.Sprintf("%s?%s", BooksUrl, data.Encode()), "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -924,29 +1259,24 @@ func (c *RestConfig) Books(instId, sz string) (*Book, error) {
 
 // BookOrders 获取解析为订单的形式的产品深度数据
 func (c *RestConfig) BookOrders(instId, sz string) ([]*BookOrder, []*BookOrder, error) {
-	books, err := c.Books(instId, sz)
+	return c.BookOrdersWithContext(context.Background(), instId, sz)
+}
+
+// BookOrdersWithContext 同BookOrders，使用ctx控制请求的取消与超时
+func (c *RestConfig) BookOrdersWithContext(ctx context.Context, instId, sz string) ([]*BookOrder, []*BookOrder, error) {
+	books, err := c.BooksWithContext(ctx, instId, sz)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	var asks []*BookOrder
-	for _, item := range books.Asks {
-		asks = append(asks, &BookOrder{
-			Price:         utils.MustParseFloat64(item[0]),
-			Amount:        utils.MustParseFloat64(item[1]),
-			OrderQuantity: utils.MustParseInt64(item[3]),
-			Value:         math.Round(utils.MustParseFloat64(item[1]) * utils.MustParseFloat64(item[0])),
-		})
+	asks, err := parseBookOrders(books.Asks)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	var bids []*BookOrder
-	for _, item := range books.Bids {
-		bids = append(bids, &BookOrder{
-			Price:         utils.MustParseFloat64(item[0]),
-			Amount:        utils.MustParseFloat64(item[1]),
-			OrderQuantity: utils.MustParseInt64(item[3]),
-			Value:         math.Round(utils.MustParseFloat64(item[1]) * utils.MustParseFloat64(item[0])),
-		})
+	bids, err := parseBookOrders(books.Bids)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	sort.Slice(asks, func(i, j int) bool {
@@ -961,6 +1291,11 @@ func (c *RestConfig) BookOrders(instId, sz string) ([]*BookOrder, []*BookOrder,
 }
 
 func (c *RestConfig) FundingRateArbitrage() ([]*FundingRateArbitrage, error) {
+	return c.FundingRateArbitrageWithContext(context.Background())
+}
+
+// FundingRateArbitrageWithContext 同FundingRateArbitrage，使用ctx控制请求的取消与超时
+func (c *RestConfig) FundingRateArbitrageWithContext(ctx context.Context) ([]*FundingRateArbitrage, error) {
 	data := url.Values{
 		"ctType":        {"linear"},
 		"ccyType":       {"USDT"},
@@ -970,7 +1305,7 @@ func (c *RestConfig) FundingRateArbitrage() ([]*FundingRateArbitrage, error) {
 	}
 
 	var fundingRateArbitrages []*FundingRateArbitrage
-	_, err := c.request(nil, &fundingRateArbitrages, http.MethodGet, "", fmt.Sprintf("%s?%s", "https://www.okx.com/priapi/v5/rubik/web/public/funding-rate-arbitrage", data.Encode()), true)
+	_, err := c.request(ctx, nil, &fundingRateArbitrages, http.MethodGet, "", fmt.Sprintf("%s?%s", "https://www.okx.com/priapi/v5/rubik/web/public/funding-rate-arbitrage", data.Encode()), true)
 	if err != nil {
 		return nil, err
 	}
@@ -979,6 +1314,11 @@ func (c *RestConfig) FundingRateArbitrage() ([]*FundingRateArbitrage, error) {
 }
 
 func (c *RestConfig) TradeFee(instId, instType, uly, instFamily string) (*TradeFee, error) {
+	return c.TradeFeeWithContext(context.Background(), instId, instType, uly, instFamily)
+}
+
+// TradeFeeWithContext 同TradeFee，使用ctx控制请求的取消与超时
+func (c *RestConfig) TradeFeeWithContext(ctx context.Context, instId, instType, uly, instFamily string) (*TradeFee, error) {
 	data := url.Values{
 		"instId":     {instId},
 		"instType":   {instType},
@@ -987,7 +1327,7 @@ func (c *RestConfig) TradeFee(instId, instType, uly, instFamily string) (*TradeF
 	}
 
 	var tradeFee []*TradeFee
-	_, err := c.request(nil, &tradeFee, http.MethodGet, fmt.Sprintf("%s?%s", TradeFeeUrl, data.Encode()), "", false)
+	_, err := c.request(ctx, nil, &tradeFee, http.MethodGet, fmt.Sprintf("%s?%s", TradeFeeUrl, data.Encode()), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -1001,13 +1341,18 @@ func (c *RestConfig) TradeFee(instId, instType, uly, instFamily string) (*TradeF
 
 // InterestLimits 获取借币利率与限额
 func (c *RestConfig) InterestLimits(tp string, ccy string) (*InterestLimit, error) {
+	return c.InterestLimitsWithContext(context.Background(), tp, ccy)
+}
+
+// InterestLimitsWithContext 同InterestLimits，使用ctx控制请求的取消与超时
+func (c *RestConfig) InterestLimitsWithContext(ctx context.Context, tp string, ccy string) (*InterestLimit, error) {
 	data := url.Values{
 		"ccy":  {ccy},
 		"type": {tp},
 	}
 
 	var interestLimits []*InterestLimit
-	_, err := c.request(nil, &interestLimits, http.MethodGet, fmt.Sprintf("%s?%s", InterestLimitsUrl, data.Encode()), "", false)
+	_, err := c.request(ctx, nil, &interestLimits, http.MethodGet, fmt.Sprintf("%s?%s", InterestLimitsUrl, data.Encode()), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -1020,48 +1365,70 @@ func (c *RestConfig) InterestLimits(tp string, ccy string) (*InterestLimit, erro
 }
 
 // Bills 账单流水查询（最近三天）
+//
+// Deprecated: 使用ListBills
 func (c *RestConfig) Bills(instType string, ccy string, mgnMode string, ctType string, tp string, subType string, after string, before string, begin string, end string, limit string) ([]*Bill, error) {
-	data := url.Values{
-		"instType": {instType},
-		"ccy":      {ccy},
-		"mgnMode":  {mgnMode},
-		"ctType":   {ctType},
-		"type":     {tp},
-		"after":    {after},
-		"before":   {before},
-		"limit":    {limit},
-		"subType":  {subType},
-		"begin":    {begin},
-		"end":      {end},
-	}
+	return c.BillsWithContext(context.Background(), instType, ccy, mgnMode, ctType, tp, subType, after, before, begin, end, limit)
+}
 
-	var bills []*Bill
-	_, err := c.request(nil, &bills, http.MethodGet, fmt.Sprintf("%s?%s", BillsUrl, data.Encode()), "", false)
+// BillsWithContext 同Bills，使用ctx控制请求的取消与超时
+//
+// Deprecated: 使用ListBillsWithContext
+func (c *RestConfig) BillsWithContext(ctx context.Context, instType string, ccy string, mgnMode string, ctType string, tp string, subType string, after string, before string, begin string, end string, limit string) ([]*Bill, error) {
+	req, err := newBillsRequest(instType, ccy, mgnMode, ctType, tp, subType, after, before, begin, end, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	return bills, nil
+	return c.ListBillsWithContext(ctx, req)
+}
+
+// ListBills 账单流水查询（最近三天）
+func (c *RestConfig) ListBills(req *BillsRequest) ([]*Bill, error) {
+	return c.ListBillsWithContext(context.Background(), req)
+}
+
+// ListBillsWithContext 同ListBills，使用ctx控制请求的取消与超时
+func (c *RestConfig) ListBillsWithContext(ctx context.Context, req *BillsRequest) ([]*Bill, error) {
+	return c.listBills(ctx, BillsUrl, req)
 }
 
 // BillsArchive 账单流水查询（近三个月）
+//
+// Deprecated: 使用ListBillsArchive
 func (c *RestConfig) BillsArchive(instType string, ccy string, mgnMode string, ctType string, tp string, subType string, after string, before string, begin string, end string, limit string) ([]*Bill, error) {
-	data := url.Values{
-		"instType": {instType},
-		"ccy":      {ccy},
-		"mgnMode":  {mgnMode},
-		"ctType":   {ctType},
-		"type":     {tp},
-		"after":    {after},
-		"before":   {before},
-		"limit":    {limit},
-		"subType":  {subType},
-		"begin":    {begin},
-		"end":      {end},
+	return c.BillsArchiveWithContext(context.Background(), instType, ccy, mgnMode, ctType, tp, subType, after, before, begin, end, limit)
+}
+
+// BillsArchiveWithContext 同BillsArchive，使用ctx控制请求的取消与超时
+//
+// Deprecated: 使用ListBillsArchiveWithContext
+func (c *RestConfig) BillsArchiveWithContext(ctx context.Context, instType string, ccy string, mgnMode string, ctType string, tp string, subType string, after string, before string, begin string, end string, limit string) ([]*Bill, error) {
+	req, err := newBillsRequest(instType, ccy, mgnMode, ctType, tp, subType, after, before, begin, end, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ListBillsArchiveWithContext(ctx, req)
+}
+
+// ListBillsArchive 账单流水查询（近三个月）
+func (c *RestConfig) ListBillsArchive(req *BillsRequest) ([]*Bill, error) {
+	return c.ListBillsArchiveWithContext(context.Background(), req)
+}
+
+// ListBillsArchiveWithContext 同ListBillsArchive，使用ctx控制请求的取消与超时
+func (c *RestConfig) ListBillsArchiveWithContext(ctx context.Context, req *BillsRequest) ([]*Bill, error) {
+	return c.listBills(ctx, BillsArchiveUrl, req)
+}
+
+func (c *RestConfig) listBills(ctx context.Context, addr string, req *BillsRequest) ([]*Bill, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
 
 	var bills []*Bill
-	_, err := c.request(nil, &bills, http.MethodGet, fmt.Sprintf("%s?%s", BillsArchiveUrl, data.Encode()), "", false)
+	_, err := c.request(ctx, nil, &bills, http.MethodGet, fmt.Sprintf("%s?%s", addr, encodeQuery(req)), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -1069,19 +1436,77 @@ func (c *RestConfig) BillsArchive(instType string, ccy string, mgnMode string, c
 	return bills, nil
 }
 
+func newBillsRequest(instType, ccy, mgnMode, ctType, tp, subType, after, before, begin, end, limit string) (*BillsRequest, error) {
+	req := &BillsRequest{
+		InstType: instType,
+		Ccy:      ccy,
+		MgnMode:  mgnMode,
+		CtType:   ctType,
+		Type:     tp,
+		SubType:  subType,
+		After:    after,
+		Before:   before,
+	}
+
+	var err error
+	if req.Begin, err = parseMilli("begin", begin); err != nil {
+		return nil, err
+	}
+	if req.End, err = parseMilli("end", end); err != nil {
+		return nil, err
+	}
+	if req.Limit, err = atoi("limit", limit); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// InterestAccrued 获取计息记录
+//
+// Deprecated: 使用ListInterestAccrued
 func (c *RestConfig) InterestAccrued(instId, ccy, tp, mgnMode, after, before, limit string) ([]*InterestAccrued, error) {
-	data := url.Values{
-		"instId":  {instId},
-		"ccy":     {ccy},
-		"mgnMode": {mgnMode},
-		"type":    {tp},
-		"after":   {after},
-		"before":  {before},
-		"limit":   {limit},
+	return c.InterestAccruedWithContext(context.Background(), instId, ccy, tp, mgnMode, after, before, limit)
+}
+
+// InterestAccruedWithContext 同InterestAccrued，使用ctx控制请求的取消与超时
+//
+// Deprecated: 使用ListInterestAccruedWithContext
+func (c *RestConfig) InterestAccruedWithContext(ctx context.Context, instId, ccy, tp, mgnMode, after, before, limit string) ([]*InterestAccrued, error) {
+	req := &InterestAccruedRequest{
+		InstId:  instId,
+		Ccy:     ccy,
+		Type:    tp,
+		MgnMode: mgnMode,
+	}
+
+	var err error
+	if req.After, err = parseMilli("after", after); err != nil {
+		return nil, err
+	}
+	if req.Before, err = parseMilli("before", before); err != nil {
+		return nil, err
+	}
+	if req.Limit, err = atoi("limit", limit); err != nil {
+		return nil, err
+	}
+
+	return c.ListInterestAccruedWithContext(ctx, req)
+}
+
+// ListInterestAccrued 获取计息记录
+func (c *RestConfig) ListInterestAccrued(req *InterestAccruedRequest) ([]*InterestAccrued, error) {
+	return c.ListInterestAccruedWithContext(context.Background(), req)
+}
+
+// ListInterestAccruedWithContext 同ListInterestAccrued，使用ctx控制请求的取消与超时
+func (c *RestConfig) ListInterestAccruedWithContext(ctx context.Context, req *InterestAccruedRequest) ([]*InterestAccrued, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
 
 	var interestAccrued []*InterestAccrued
-	_, err := c.request(nil, &interestAccrued, http.MethodGet, fmt.Sprintf("%s?%s", InterestAccruedUrl, data.Encode()), "", false)
+	_, err := c.request(ctx, nil, &interestAccrued, http.MethodGet, fmt.Sprintf("%s?%s", InterestAccruedUrl, encodeQuery(req)), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -1090,12 +1515,17 @@ func (c *RestConfig) InterestAccrued(instId, ccy, tp, mgnMode, after, before, li
 }
 
 func (c *RestConfig) InterestRate(ccy string) ([]*InterestRate, error) {
+	return c.InterestRateWithContext(context.Background(), ccy)
+}
+
+// InterestRateWithContext 同InterestRate，使用ctx控制请求的取消与超时
+func (c *RestConfig) InterestRateWithContext(ctx context.Context, ccy string) ([]*InterestRate, error) {
 	data := url.Values{
 		"ccy": {ccy},
 	}
 
 	var interestRate []*InterestRate
-	_, err := c.request(nil, &interestRate, http.MethodGet, fmt.Sprintf("%s?%s", InterestRateUrl, data.Encode()), "", false)
+	_, err := c.request(ctx, nil, &interestRate, http.MethodGet, fmt.Sprintf("%s?%s", InterestRateUrl, data.Encode()), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -1103,25 +1533,44 @@ func (c *RestConfig) InterestRate(ccy string) ([]*InterestRate, error) {
 	return interestRate, nil
 }
 
-func (c *RestConfig) request(msg interface{}, res interface{}, method string, addr string, customize string, public bool) (*ResponseBean, error) {
-	host := RestGlobalUrl
-	if c.Simulate {
-		host = RestSimulateUrl
+// request 发送请求，可重试的错误按RetryPolicy重试
+func (c *RestConfig) request(ctx context.Context, msg interface{}, res interface{}, method string, addr string, customize string, public bool) (*ResponseBean, error) {
+	path := addr
+	if customize != "" {
+		path = customize
 	}
+	path, _, _ = strings.Cut(path, "?")
 
-	client := &http.Client{
-		Timeout: time.Duration(1) * time.Second,
+	if !public {
+		c.syncTimeIfNeeded(ctx, false)
 	}
 
-	if c.Proxy != "" {
-		u, _ := url.Parse(c.Proxy)
-		t := &http.Transport{
-			MaxIdleConns:    10,
-			MaxConnsPerHost: 10,
-			IdleConnTimeout: time.Duration(3) * time.Second,
-			Proxy:           http.ProxyURL(u),
+	policy := c.retryPolicy()
+	for attempt := 1; ; attempt++ {
+		resp, err := c.do(ctx, msg, res, method, addr, customize, path, public)
+		if err != nil && !public && attempt < policy.MaxAttempts && IsTimestampExpired(err) {
+			// 时间戳过期的请求不会被交易所处理，重新校时后可以安全重试
+			c.syncTimeIfNeeded(ctx, true)
+			continue
+		}
+
+		if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(err, method, path, msg) {
+			return resp, err
+		}
+
+		delay := policy.backoff(attempt, err)
+		logx.Infof("[okx] retry %s %s after %v, attempt: %d, err: %v", method, path, delay, attempt, err)
+		if err = sleepContext(ctx, delay); err != nil {
+			return nil, err
 		}
-		client.Transport = t
+	}
+}
+
+func (c *RestConfig) do(ctx context.Context, msg interface{}, res interface{}, method, addr, customize, path string, public bool) (*ResponseBean, error) {
+	host, simulate := c.endpoint()
+
+	if err := c.rateLimiter().Wait(ctx, path, requestInstIds(msg, addr)...); err != nil {
+		return nil, err
 	}
 
 	var body []byte
@@ -1137,7 +1586,7 @@ func (c *RestConfig) request(msg interface{}, res interface{}, method string, ad
 		u = customize
 	}
 
-	r, err := http.NewRequest(method, u, strings.NewReader(string(body)))
+	r, err := http.NewRequestWithContext(ctx, method, u, strings.NewReader(string(body)))
 	if err != nil {
 		return nil, err
 	}
@@ -1147,18 +1596,22 @@ func (c *RestConfig) request(msg interface{}, res interface{}, method string, ad
 	}
 
 	if !public {
-		timestamp := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+		timestamp := c.now().UTC().Format("2006-01-02T15:04:05.000Z")
 		r.Header.Set("OK-ACCESS-KEY", c.ApiKey)
 		r.Header.Set("OK-ACCESS-SIGN", c.getAccessSign(method, addr, string(body), timestamp))
 		r.Header.Set("OK-ACCESS-TIMESTAMP", timestamp)
 		r.Header.Set("OK-ACCESS-PASSPHRASE", c.Password)
 	}
 
-	if c.Simulate {
+	if expTime := c.expTime(path); expTime != "" {
+		r.Header.Set("expTime", expTime)
+	}
+
+	if simulate {
 		r.Header.Set("x-simulated-trading", "1")
 	}
 
-	rsp, err := client.Do(r)
+	rsp, err := c.httpClient().Do(r)
 	if err != nil {
 		return nil, err
 	}
@@ -1172,27 +1625,103 @@ func (c *RestConfig) request(msg interface{}, res interface{}, method string, ad
 
 	var resp ResponseBean
 	if err = json.Unmarshal(ret, &resp); err != nil {
+		if rsp.StatusCode != http.StatusOK {
+			return nil, &APIError{HTTPStatus: rsp.StatusCode, Method: method, Path: path, Msg: truncate(string(ret), 256)}
+		}
 		return nil, err
 	}
 
-	if resp.Code != "0" {
-		return nil, fmt.Errorf("msg: %s, data: %+v", resp.Msg, resp.Data)
-	}
-
 	if res != nil && len(resp.Data) > 0 {
 		bytes, err := json.Marshal(resp.Data)
 		if err != nil {
 			return nil, err
 		}
 
-		if err = json.Unmarshal(bytes, res); err != nil {
+		// 批量接口部分成功时也需要返回数据
+		if err = json.Unmarshal(bytes, res); err != nil && resp.Code == CodeSuccess {
 			return nil, err
 		}
 	}
 
+	if resp.Code != CodeSuccess {
+		return &resp, newAPIError(&resp, rsp.StatusCode, method, path)
+	}
+
 	return &resp, nil
 }
 
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n] + "..."
+}
+
+// httpClient 返回复用的http.Client，首次调用时根据Proxy和Timeout创建
+func (c *RestConfig) httpClient() *http.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client == nil {
+		c.client = &http.Client{
+			Timeout:   c.timeout(),
+			Transport: c.newTransport(),
+		}
+	}
+
+	return c.client
+}
+
+// endpoint 返回当前的rest地址和模拟盘标识
+func (c *RestConfig) endpoint() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	host := c.Host
+	if host == "" {
+		host = DefaultEndpoint(c.Simulate).Rest
+	}
+
+	return strings.TrimRight(host, "/"), c.Simulate
+}
+
+// rateLimiter 返回本地限速器，默认按RateLimitWait策略限速
+func (c *RestConfig) rateLimiter() *RateLimiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.limiter == nil {
+		c.limiter = NewRateLimiter(RateLimitWait)
+	}
+
+	return c.limiter
+}
+
+func (c *RestConfig) newTransport() http.RoundTripper {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = 100
+	t.MaxIdleConnsPerHost = 10
+	t.IdleConnTimeout = 90 * time.Second
+
+	if c.Proxy != "" {
+		u, err := url.Parse(c.Proxy)
+		if err == nil {
+			t.Proxy = http.ProxyURL(u)
+		}
+	}
+
+	return t
+}
+
+func (c *RestConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultTimeout
+	}
+
+	return time.Duration(c.Timeout) * time.Second
+}
+
 func (c *RestConfig) getAccessSign(method, requestPath, body, timestamp string) string {
 	// OK-ACCESS-SIGN的请求头是对timestamp + method + requestPath + body字符串（+表示字符串连接），以及SecretKey，使用HMAC SHA256方法加密，通过Base-64编码输出而得到的。
 	return base64.StdEncoding.EncodeToString(hmacSha256(c.SecretKey, timestamp+method+requestPath+body))
@@ -1,16 +1,230 @@
 package okx
 
 import (
+	"context"
 	"fmt"
 	"github.com/hansdq/go-okx/common/utils"
+	"io"
 	"math"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
 var apiConfig = InitRestConfig("", "", "", "", true)
 
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// stubResponse 构造一个返回固定内容的http响应
+func stubResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// newStubConfig 返回一个由fn处理所有请求的RestConfig，关闭自动校时以免产生额外请求
+func newStubConfig(fn roundTripFunc) *RestConfig {
+	c := InitRestConfig("", "", "", "", false)
+	c.SetTimeSync(0)
+	c.SetTransport(fn)
+	return c
+}
+
+func TestSetTransport(t *testing.T) {
+	var calls int
+	c := InitRestConfig("", "", "", "", false)
+	c.Timeout = 3
+	c.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return stubResponse(http.StatusOK, `{"code":"0","msg":"","data":[{"ts":"1597026383085"}]}`), nil
+	}))
+
+	if c.httpClient().Timeout != 3*time.Second {
+		t.Errorf("timeout = %v, want 3s", c.httpClient().Timeout)
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := c.GetTime()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Ts != "1597026383085" {
+			t.Errorf("ts = %s", res.Ts)
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestRequestContext(t *testing.T) {
+	c := InitRestConfig("", "", "", "", false)
+	c.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if _, ok := r.Context().Deadline(); !ok {
+			t.Error("request should carry the caller deadline")
+		}
+		return nil, r.Context().Err()
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	cancel()
+
+	if _, err := c.TickerWithContext(ctx, "BTC-USDT"); err == nil {
+		t.Error("canceled context should fail the request")
+	}
+}
+
+func TestHost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != TimeUrl || r.Header.Get("x-simulated-trading") != "1" {
+			t.Errorf("unexpected request: %s %v", r.URL, r.Header)
+		}
+		_, _ = w.Write([]byte(`{"code":"0","msg":"","data":[{"ts":"1597026383085"}]}`))
+	}))
+	defer server.Close()
+
+	e, err := GetEndpoint(DemoRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Rest = server.URL + "/"
+
+	c := InitRestConfig("", "", "", "", false)
+	c.SetEndpoint(e)
+	res, err := c.GetTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Ts != "1597026383085" {
+		t.Errorf("ts = %s", res.Ts)
+	}
+
+	if err = c.SetRegion("mars"); err == nil {
+		t.Error("unknown region should fail")
+	}
+}
+
+func TestSetEndpointConcurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":"0","msg":"","data":[{"ts":"1597026383085"}]}`))
+	}))
+	defer server.Close()
+
+	c := InitRestConfig("", "", "", "", false)
+	c.SetEndpoint(Endpoint{Rest: server.URL})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 4; j++ {
+				if _, err := c.GetTime(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		c.SetEndpoint(Endpoint{Rest: server.URL + "/", Simulate: i%2 == 0})
+	}
+	wg.Wait()
+}
+
+func TestHttpClientReuse(t *testing.T) {
+	c := InitRestConfig("", "", "", "", false)
+	if c.httpClient() != c.httpClient() {
+		t.Error("http client should be reused")
+	}
+
+	if c.httpClient().Timeout != DefaultTimeout {
+		t.Errorf("timeout = %v, want %v", c.httpClient().Timeout, DefaultTimeout)
+	}
+}
+
+func TestMakeOrderMmp(t *testing.T) {
+	var body string
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		return stubResponse(http.StatusOK, `{"code":"0","msg":"","data":[{"ordId":"1","clOrdId":"","tag":"","sCode":"0","sMsg":""}]}`), nil
+	})
+
+	// ordType不在常量中时也要交给交易所处理
+	order, err := c.MakeOrder("BTC-USD-240628-60000-C", Isolated, "", "", Buy, "mmp", "0.01", "1", false, "", "", false, nil)
+	if err != nil || order.OrdId != "1" {
+		t.Fatalf("order = %+v, err = %v", order, err)
+	}
+	if !strings.Contains(body, `"ordType":"mmp"`) {
+		t.Errorf("request = %s", body)
+	}
+}
+
+func TestAmendOrder(t *testing.T) {
+	var bodies []string
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, r.URL.Path+" "+string(b))
+		if r.URL.Path == AmendBatchOrdersUrl {
+			return stubResponse(http.StatusOK, `{"code":"2","msg":"","data":[{"ordId":"1","reqId":"r1","sCode":"0","sMsg":""},{"clOrdId":"a2","sCode":"51503","sMsg":"Order modification failed as the order does not exist."}]}`), nil
+		}
+		return stubResponse(http.StatusOK, `{"code":"0","msg":"","data":[{"ordId":"1","clOrdId":"","reqId":"r1","ts":"1695190491421","sCode":"0","sMsg":""}]}`), nil
+	})
+
+	res, err := c.AmendOrder(&AmendOrderRequest{
+		InstId:         "BTC-USDT-SWAP",
+		OrdId:          "1",
+		ReqId:          "r1",
+		NewPx:          "40000",
+		CxlOnFail:      true,
+		AttachAlgoOrds: []*AmendTrigger{{AttachAlgoId: "2", NewSlTriggerPx: "39000", NewSlOrdPx: "-1"}},
+	})
+	if err != nil || res.OrdId != "1" || res.ReqId != "r1" {
+		t.Fatalf("res = %+v, err = %v", res, err)
+	}
+
+	want := AmendOrderUrl + ` {"instId":"BTC-USDT-SWAP","cxlOnFail":true,"ordId":"1","reqId":"r1","newPx":"40000","attachAlgoOrds":[{"attachAlgoId":"2","newSlTriggerPx":"39000","newSlOrdPx":"-1"}]}`
+	if bodies[0] != want {
+		t.Errorf("request = %s, want %s", bodies[0], want)
+	}
+
+	results, err := c.BatchAmendOrders([]*AmendOrderRequest{
+		{InstId: "BTC-USDT", OrdId: "1", ReqId: "r1", NewSz: "2"},
+		{InstId: "ETH-USDT", ClOrdId: "a2", NewPx: "2000"},
+	})
+	if len(results) != 2 || results[0].OrdId != "1" {
+		t.Errorf("partial results should be returned, got %+v", results)
+	}
+
+	apiErr, ok := AsAPIError(err)
+	if !ok || len(apiErr.Items) != 1 || apiErr.Items[0].ClOrdId != "a2" || apiErr.Path != AmendBatchOrdersUrl {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err = c.BatchAmendOrders([]*AmendOrderRequest{{InstId: "BTC-USDT", OrdId: "1"}}); err == nil || len(bodies) != 2 {
+		t.Errorf("invalid request should fail before sending, err = %v", err)
+	}
+
+	if _, err = c.BatchAmendOrders(nil); err == nil || len(bodies) != 2 {
+		t.Errorf("empty request should fail before sending, err = %v", err)
+	}
+}
+
 func TestGetTime(t *testing.T) {
 	res, err := apiConfig.GetTime()
 	if err != nil {
@@ -0,0 +1,299 @@
+package okx
+
+import (
+	"context"
+	"fmt"
+	"github.com/zeromicro/go-zero/core/logx"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxBatchSize 批量接口每次请求的最大订单数
+const maxBatchSize = 20
+
+// CancelAllAfterResult 撤单倒计时的设置结果
+type CancelAllAfterResult struct {
+	TriggerTime string `json:"triggerTime"` // 触发撤单的时间，毫秒，为0时表示已取消倒计时
+	Tag         string `json:"tag"`
+	Ts          string `json:"ts"`
+}
+
+// BatchCancelOrders 批量撤单，超过20个时分多次请求
+// 部分失败时同时返回结果和*APIError，APIError.Items中的Index为在reqs中的位置，中途请求整体失败时返回*BatchError
+func (c *RestConfig) BatchCancelOrders(reqs []*CancelOrderRequest) ([]*Order, error) {
+	return c.BatchCancelOrdersWithContext(context.Background(), reqs)
+}
+
+// BatchCancelOrdersWithContext 同BatchCancelOrders，使用ctx控制请求的取消与超时
+func (c *RestConfig) BatchCancelOrdersWithContext(ctx context.Context, reqs []*CancelOrderRequest) ([]*Order, error) {
+	for _, req := range reqs {
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return requestChunked[*CancelOrderRequest, *Order](ctx, c, CancelBatchOrdersUrl, reqs, maxBatchSize)
+}
+
+// requestChunked 把批量请求按size分成多次发送，合并返回的结果
+// 部分失败时同时返回结果和合并后的*APIError，Items中的Index为在reqs中的位置
+// 第一次之后的请求整体失败时返回已有的结果和*BatchError
+func requestChunked[Q, R any](ctx context.Context, c *RestConfig, path string, reqs []Q, size int) ([]R, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("reqs is required")
+	}
+
+	var ret []R
+	var merged *APIError
+	for start := 0; start < len(reqs); start += size {
+		end := start + size
+		if end > len(reqs) {
+			end = len(reqs)
+		}
+
+		var items []R
+		_, err := c.request(ctx, reqs[start:end], &items, http.MethodPost, path, "", false)
+		if err == nil {
+			ret = append(ret, items...)
+			continue
+		}
+
+		// 没有逐项结果时无法继续对应请求中的位置
+		apiErr, ok := AsAPIError(err)
+		if !ok || len(apiErr.Items) == 0 || len(items) != end-start {
+			if start == 0 {
+				return ret, err
+			}
+
+			if merged != nil {
+				merged.Code = CodeBatchPartial
+			}
+			return ret, &BatchError{Err: err, Start: start, Partial: merged}
+		}
+
+		ret = append(ret, items...)
+		if merged == nil {
+			merged = &APIError{Msg: apiErr.Msg, HTTPStatus: apiErr.HTTPStatus, Method: apiErr.Method, Path: apiErr.Path}
+		}
+		for _, item := range apiErr.Items {
+			item.Index += start
+			merged.Items = append(merged.Items, item)
+		}
+	}
+
+	if merged == nil {
+		return ret, nil
+	}
+
+	merged.Code = CodeBatchPartial
+	if len(merged.Items) == len(reqs) {
+		merged.Code = CodeOperationFailed
+	}
+
+	return ret, merged
+}
+
+// MassCancel 撤销instFamily下所有的期权订单，lockInterval为撤单后禁止交易的时间，最长10秒
+func (c *RestConfig) MassCancel(instFamily string, lockInterval time.Duration) error {
+	return c.MassCancelWithContext(context.Background(), instFamily, lockInterval)
+}
+
+// MassCancelWithContext 同MassCancel，使用ctx控制请求的取消与超时
+func (c *RestConfig) MassCancelWithContext(ctx context.Context, instFamily string, lockInterval time.Duration) error {
+	if err := checkRequired("instFamily", instFamily); err != nil {
+		return err
+	}
+
+	data := Params{"instType": OPTION, "instFamily": instFamily}
+	if lockInterval > 0 {
+		data["lockInterval"] = strconv.FormatInt(lockInterval.Milliseconds(), 10)
+	}
+
+	var ret []struct {
+		Result bool `json:"result"`
+	}
+	if _, err := c.request(ctx, data, &ret, http.MethodPost, MassCancelUrl, "", false); err != nil {
+		return err
+	}
+
+	if len(ret) == 0 || !ret[0].Result {
+		return fmt.Errorf("mass cancel %s failed", instFamily)
+	}
+
+	return nil
+}
+
+// CancelAllAfter 设置撤单倒计时，timeout内没有再次调用时撤销所有订单
+// timeout为0时取消倒计时，否则需要在10秒到120秒之间，tag不为空时只撤销该标签的订单
+func (c *RestConfig) CancelAllAfter(timeout time.Duration, tag string) (*CancelAllAfterResult, error) {
+	return c.CancelAllAfterWithContext(context.Background(), timeout, tag)
+}
+
+// CancelAllAfterWithContext 同CancelAllAfter，使用ctx控制请求的取消与超时
+func (c *RestConfig) CancelAllAfterWithContext(ctx context.Context, timeout time.Duration, tag string) (*CancelAllAfterResult, error) {
+	if timeout != 0 && (timeout < 10*time.Second || timeout > 120*time.Second) {
+		return nil, fmt.Errorf("timeout must be 0 or between 10s and 120s")
+	}
+
+	data := Params{"timeOut": strconv.FormatInt(int64(timeout/time.Second), 10)}
+	if tag != "" {
+		data["tag"] = tag
+	}
+
+	var ret []*CancelAllAfterResult
+	if _, err := c.request(ctx, data, &ret, http.MethodPost, CancelAllAfterUrl, "", false); err != nil {
+		return nil, err
+	}
+
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("empty cancel-all-after response")
+	}
+
+	return ret[0], nil
+}
+
+// CancelAllAfterKeeper 在后台定期刷新撤单倒计时，进程崩溃或健康检查失败不再刷新时交易所会撤销所有订单
+type CancelAllAfterKeeper struct {
+	c        *RestConfig
+	timeout  time.Duration
+	interval time.Duration
+	tag      string
+	health   func(ctx context.Context) error
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	result *CancelAllAfterResult
+	err    error
+}
+
+// KeepCancelAllAfter 设置撤单倒计时并在后台每timeout/4刷新一次，连续两次刷新失败也不会触发撤单
+// 每次刷新前调用health检查策略是否正常，返回错误或超过刷新间隔没有返回时跳过本次刷新，倒计时结束后交易所撤销所有订单
+// 第一次设置失败时返回错误，不再需要时调用Stop取消倒计时
+func (c *RestConfig) KeepCancelAllAfter(timeout time.Duration, tag string, health func(ctx context.Context) error) (*CancelAllAfterKeeper, error) {
+	return c.keepCancelAllAfter(timeout, keepInterval(timeout), tag, health)
+}
+
+// keepInterval 刷新间隔，连续两次刷新失败后第三次刷新仍在倒计时结束之前
+func keepInterval(timeout time.Duration) time.Duration {
+	return timeout / 4
+}
+
+func (c *RestConfig) keepCancelAllAfter(timeout, interval time.Duration, tag string, health func(ctx context.Context) error) (*CancelAllAfterKeeper, error) {
+	if timeout == 0 {
+		return nil, fmt.Errorf("timeout is required")
+	}
+
+	if health == nil {
+		return nil, fmt.Errorf("health is required")
+	}
+
+	result, err := c.CancelAllAfter(timeout, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	k := &CancelAllAfterKeeper{
+		c:        c,
+		timeout:  timeout,
+		interval: interval,
+		tag:      tag,
+		health:   health,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		result:   result,
+	}
+
+	go k.run(ctx)
+	return k, nil
+}
+
+// TriggerTime 最近一次成功刷新后触发撤单的时间
+func (k *CancelAllAfterKeeper) TriggerTime() time.Time {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	ms, _ := strconv.ParseInt(k.result.TriggerTime, 10, 64)
+	return time.UnixMilli(ms)
+}
+
+// Err 最近一次刷新或健康检查的错误，刷新成功时为nil
+func (k *CancelAllAfterKeeper) Err() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.err
+}
+
+// checkHealth 调用health，超过刷新间隔没有返回时视为失败，不等待health返回
+func (k *CancelAllAfterKeeper) checkHealth(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, k.interval)
+	defer cancel()
+
+	ch := make(chan error, 1)
+	go func() {
+		ch <- k.health(ctx)
+	}()
+
+	select {
+	case err := <-ch:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop 停止刷新并取消倒计时
+func (k *CancelAllAfterKeeper) Stop() error {
+	k.cancel()
+	<-k.done
+
+	_, err := k.c.CancelAllAfter(0, k.tag)
+	return err
+}
+
+func (k *CancelAllAfterKeeper) run(ctx context.Context) {
+	defer close(k.done)
+
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := k.checkHealth(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			logx.Errorf("[okx] unhealthy, skip refreshing cancel-all-after: %v", err)
+			k.mu.Lock()
+			k.err = fmt.Errorf("health check: %w", err)
+			k.mu.Unlock()
+			continue
+		}
+
+		result, err := k.c.CancelAllAfterWithContext(ctx, k.timeout, k.tag)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			logx.Errorf("[okx] refresh cancel-all-after failed: %v", err)
+		}
+
+		k.mu.Lock()
+		k.err = err
+		if err == nil {
+			k.result = result
+		}
+		k.mu.Unlock()
+	}
+}
@@ -0,0 +1,191 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchCancelOrders(t *testing.T) {
+	var sizes []int
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		var reqs []*CancelOrderRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(reqs))
+
+		// 第二批的第一个订单撤单失败
+		var items []string
+		code := CodeSuccess
+		for i, req := range reqs {
+			sCode := CodeSuccess
+			if len(sizes) == 2 && i == 0 {
+				sCode, code = "51400", CodeBatchPartial
+			}
+			items = append(items, fmt.Sprintf(`{"ordId":"%s","clOrdId":"","sCode":"%s","sMsg":""}`, req.OrdId, sCode))
+		}
+		return stubResponse(http.StatusOK, fmt.Sprintf(`{"code":"%s","msg":"","data":[%s]}`, code, strings.Join(items, ","))), nil
+	})
+	c.SetRateLimiter(NewRateLimiter(RateLimitDisabled))
+
+	var reqs []*CancelOrderRequest
+	for i := 0; i < 45; i++ {
+		reqs = append(reqs, &CancelOrderRequest{InstId: "BTC-USDT", OrdId: fmt.Sprint(i)})
+	}
+
+	orders, err := c.BatchCancelOrders(reqs)
+	if fmt.Sprint(sizes) != "[20 20 5]" || len(orders) != 45 || orders[44].OrdId != "44" {
+		t.Errorf("sizes = %v, orders = %d", sizes, len(orders))
+	}
+
+	apiErr, ok := AsAPIError(err)
+	if !ok || apiErr.Code != CodeBatchPartial || len(apiErr.Items) != 1 || apiErr.Items[0].Index != 20 || apiErr.Items[0].OrdId != "20" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err = c.BatchCancelOrders([]*CancelOrderRequest{{InstId: "BTC-USDT"}}); err == nil {
+		t.Error("request without ordId should fail")
+	}
+}
+
+func TestBatchCancelOrdersFailed(t *testing.T) {
+	calls := 0
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		calls++
+		switch calls {
+		case 1:
+			return stubResponse(http.StatusOK, `{"code":"2","msg":"","data":[{"ordId":"0","sCode":"51400","sMsg":"cancel failed"},{"ordId":"1","sCode":"0","sMsg":""}]}`), nil
+		default:
+			return stubResponse(http.StatusOK, `{"code":"50011","msg":"Too Many Requests","data":[]}`), nil
+		}
+	})
+	c.SetRateLimiter(NewRateLimiter(RateLimitDisabled))
+	c.SetRetryPolicy(NoRetryPolicy)
+
+	var reqs []*CancelOrderRequest
+	for i := 0; i < 4; i++ {
+		reqs = append(reqs, &CancelOrderRequest{InstId: "BTC-USDT", OrdId: fmt.Sprint(i)})
+	}
+
+	// 第二批整体失败时保留第一批的单项错误
+	_, err := requestChunked[*CancelOrderRequest, *Order](context.Background(), c, CancelBatchOrdersUrl, reqs, 2)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Start != 2 || !IsRateLimited(err) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if batchErr.Partial == nil || len(batchErr.Partial.Items) != 1 || batchErr.Partial.Items[0].OrdId != "0" {
+		t.Errorf("partial = %v", batchErr.Partial)
+	}
+
+	if _, err = c.BatchCancelOrders(nil); err == nil {
+		t.Error("empty request should fail")
+	}
+}
+
+func TestCancelAllAfter(t *testing.T) {
+	var mu sync.Mutex
+	var timeouts []string
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		var data map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&data)
+
+		mu.Lock()
+		timeouts = append(timeouts, data["timeOut"])
+		mu.Unlock()
+
+		trigger := "0"
+		if data["timeOut"] != "0" {
+			trigger = "1587971460000"
+		}
+		return stubResponse(http.StatusOK, fmt.Sprintf(`{"code":"0","msg":"","data":[{"triggerTime":"%s","tag":"","ts":"1587971400000"}]}`, trigger)), nil
+	})
+	c.SetRateLimiter(NewRateLimiter(RateLimitDisabled))
+
+	if _, err := c.CancelAllAfter(5*time.Second, ""); err == nil {
+		t.Error("timeout below 10s should fail")
+	}
+
+	for _, timeout := range []time.Duration{10 * time.Second, 60 * time.Second, 120 * time.Second} {
+		if interval := keepInterval(timeout); interval*3 >= timeout {
+			t.Errorf("interval %v leaves no margin for two failed refreshes within %v", interval, timeout)
+		}
+	}
+
+	if _, err := c.KeepCancelAllAfter(60*time.Second, "", nil); err == nil {
+		t.Error("keeper without health check should fail")
+	}
+
+	var healthy atomic.Bool
+	healthy.Store(true)
+	k, err := c.keepCancelAllAfter(60*time.Second, 10*time.Millisecond, "", func(ctx context.Context) error {
+		if !healthy.Load() {
+			return errors.New("strategy stuck")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(55 * time.Millisecond)
+	if k.TriggerTime().UnixMilli() != 1587971460000 || k.Err() != nil {
+		t.Errorf("trigger time = %v, err = %v", k.TriggerTime(), k.Err())
+	}
+
+	// 健康检查失败时不再刷新
+	healthy.Store(false)
+	time.Sleep(15 * time.Millisecond)
+	mu.Lock()
+	refreshed := len(timeouts)
+	mu.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+	if err = k.Err(); err == nil {
+		t.Error("health check error not reported")
+	}
+	if err = k.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if refreshed < 4 || len(timeouts) != refreshed+1 || timeouts[0] != "60" || timeouts[len(timeouts)-1] != "0" {
+		t.Errorf("refreshed = %d, timeouts = %v", refreshed, timeouts)
+	}
+}
+
+func TestCancelAllAfterHealthTimeout(t *testing.T) {
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusOK, `{"code":"0","msg":"","data":[{"triggerTime":"1587971460000","tag":"","ts":"1587971400000"}]}`), nil
+	})
+	c.SetRateLimiter(NewRateLimiter(RateLimitDisabled))
+
+	// 一直阻塞的健康检查按超时处理，不影响Stop
+	hang := make(chan struct{})
+	defer close(hang)
+	k, err := c.keepCancelAllAfter(60*time.Second, 10*time.Millisecond, "", func(ctx context.Context) error {
+		<-hang
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(35 * time.Millisecond)
+	if err = k.Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want deadline exceeded", err)
+	}
+
+	start := time.Now()
+	if err = k.Stop(); err != nil || time.Since(start) > time.Second {
+		t.Errorf("stop took %v, err = %v", time.Since(start), err)
+	}
+}
@@ -0,0 +1,114 @@
+package okx
+
+import (
+	"context"
+	"fmt"
+	"github.com/hansdq/go-okx/common/utils"
+	"github.com/zeromicro/go-zero/core/logx"
+	"strconv"
+	"time"
+)
+
+// expTimeEndpoints 支持expTime请求头的接口
+var expTimeEndpoints = map[string]bool{
+	OrderUrl:            true,
+	BatchOrdersUrl:      true,
+	AmendOrderUrl:       true,
+	AmendBatchOrdersUrl: true,
+}
+
+// SyncTime 测量本地与交易所的时间偏移，之后的签名时间戳和expTime都会加上该偏移
+func (c *RestConfig) SyncTime() error {
+	return c.SyncTimeWithContext(context.Background())
+}
+
+// SyncTimeWithContext 同SyncTime，使用ctx控制请求的取消与超时
+func (c *RestConfig) SyncTimeWithContext(ctx context.Context) error {
+	start := time.Now()
+	t, err := c.GetTimeWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	end := time.Now()
+
+	// 假设请求往返耗时对半分，交易所时间对应请求的中间时刻
+	rtt := end.Sub(start)
+	server := utils.MustParseInt64(t.Ts)
+	if server <= 0 {
+		return fmt.Errorf("invalid server time: %s", t.Ts)
+	}
+
+	offset := server - start.Add(rtt/2).UnixMilli()
+	c.offset.Store(offset)
+
+	c.mu.Lock()
+	c.syncedAt = end
+	c.mu.Unlock()
+
+	logx.Infof("[okx] time offset: %dms, rtt: %v", offset, rtt)
+	return nil
+}
+
+// TimeOffset 返回交易所时间与本地时间的差值
+func (c *RestConfig) TimeOffset() time.Duration {
+	return time.Duration(c.offset.Load()) * time.Millisecond
+}
+
+// SetTimeSync 设置自动刷新时间偏移的间隔，默认DefaultTimeSyncInterval，为0时关闭自动刷新
+func (c *RestConfig) SetTimeSync(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.syncInterval = interval
+	c.syncConfigured = true
+}
+
+// SetRequestExpiry 设置下单请求的有效期，大于0时会在请求头中携带expTime，超时未处理的请求会被交易所拒绝
+func (c *RestConfig) SetRequestExpiry(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expiry = d
+}
+
+// now 返回校正后的当前时间
+func (c *RestConfig) now() time.Time {
+	return time.Now().Add(c.TimeOffset())
+}
+
+// expTime 返回path对应的expTime请求头，不需要时返回空字符串
+func (c *RestConfig) expTime(path string) string {
+	c.mu.Lock()
+	expiry := c.expiry
+	c.mu.Unlock()
+
+	if expiry <= 0 || !expTimeEndpoints[path] {
+		return ""
+	}
+
+	return strconv.FormatInt(c.now().Add(expiry).UnixMilli(), 10)
+}
+
+// syncTimeIfNeeded 时间偏移过期时刷新，失败时继续使用上一次的偏移
+func (c *RestConfig) syncTimeIfNeeded(ctx context.Context, force bool) {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+
+	c.mu.Lock()
+	interval := c.syncInterval
+	if !c.syncConfigured {
+		interval = DefaultTimeSyncInterval
+	}
+	stale := force || (interval > 0 && time.Since(c.syncedAt) > interval)
+	recent := time.Since(c.syncTried) < time.Second
+	if stale && !recent {
+		c.syncTried = time.Now()
+	}
+	c.mu.Unlock()
+
+	if !stale || recent {
+		return
+	}
+
+	if err := c.SyncTimeWithContext(ctx); err != nil {
+		logx.Errorf("[okx] sync time failed: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package okx
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTimeOffset(t *testing.T) {
+	skew := 10 * time.Second
+	var signed time.Time
+	var expTime int64
+	c := InitRestConfig("", "", "", "", false)
+	c.SetRequestExpiry(time.Second)
+	c.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == TimeUrl {
+			ts := time.Now().Add(skew).UnixMilli()
+			return stubResponse(http.StatusOK, fmt.Sprintf(`{"code":"0","msg":"","data":[{"ts":"%d"}]}`, ts)), nil
+		}
+
+		signed, _ = time.Parse("2006-01-02T15:04:05.000Z", r.Header.Get("OK-ACCESS-TIMESTAMP"))
+		expTime, _ = strconv.ParseInt(r.Header.Get("expTime"), 10, 64)
+		return stubResponse(http.StatusOK, `{"code":"0","msg":"","data":[{"ordId":"1","sCode":"0"}]}`), nil
+	}))
+
+	if _, err := c.MakeOrder("BTC-USDT", Cash, "", "", Buy, Market, "", "1", false, "", "", false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := c.TimeOffset() - skew; d < -time.Second || d > time.Second {
+		t.Errorf("offset = %v, want about %v", c.TimeOffset(), skew)
+	}
+
+	if d := signed.Sub(time.Now().Add(skew)); d < -2*time.Second || d > time.Second {
+		t.Errorf("signed timestamp %v is not adjusted", signed)
+	}
+
+	if d := time.UnixMilli(expTime).Sub(signed); d < 500*time.Millisecond || d > 2*time.Second {
+		t.Errorf("expTime %d is not adjusted", expTime)
+	}
+}
+
+func TestTimestampExpiredRetry(t *testing.T) {
+	var orders, syncs int
+	c := InitRestConfig("", "", "", "", false)
+	c.SetTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == TimeUrl {
+			syncs++
+			return stubResponse(http.StatusOK, fmt.Sprintf(`{"code":"0","msg":"","data":[{"ts":"%d"}]}`, time.Now().UnixMilli())), nil
+		}
+
+		orders++
+		if orders == 1 {
+			return stubResponse(http.StatusUnauthorized, `{"code":"50102","msg":"Timestamp request expired","data":[]}`), nil
+		}
+		return stubResponse(http.StatusOK, `{"code":"0","msg":"","data":[{"ordId":"1","sCode":"0"}]}`), nil
+	}))
+	c.SetTimeSync(0)
+
+	if _, err := c.MakeOrder("BTC-USDT", Cash, "", "", Buy, Market, "", "1", false, "", "", false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if orders != 2 || syncs != 1 {
+		t.Errorf("orders = %d, syncs = %d", orders, syncs)
+	}
+}
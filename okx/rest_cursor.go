@@ -0,0 +1,296 @@
+package okx
+
+import (
+	"context"
+	"fmt"
+	"github.com/hansdq/go-okx/common/utils"
+	"strconv"
+	"time"
+)
+
+// pageLimit 历史数据接口单页的最大条数
+const pageLimit = 100
+
+// Cursor 分页遍历历史数据，数据按时间从新到旧返回，请求受RestConfig的限速器控制
+//
+//	cur := c.IterBills(ctx, &BillsRequest{InstType: SWAP, Begin: begin, End: end})
+//	for cur.Next() {
+//		bill := cur.Item()
+//	}
+//	if err := cur.Err(); err != nil {
+//	}
+type Cursor[T any] struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, after string) ([]T, error)
+	key   func(item T) (cursor string, ts int64) // 下一页的游标和数据的时间戳
+	id    func(item T) string                    // 数据的唯一标识，不为nil时游标包含边界时间戳，重复的数据按id去掉
+	begin int64                                  // 开始时间，毫秒，0表示不限制
+	end   int64                                  // 结束时间，毫秒，0表示不限制
+
+	after string
+	edge  int64           // 上一页最后一个时间戳
+	seen  map[string]bool // 上一页在edge上的数据
+	page  []T
+	item  T
+	err   error
+	done  bool
+}
+
+// Next 移动到下一条数据，没有更多数据、出错或ctx结束时返回false
+func (c *Cursor[T]) Next() bool {
+	if c.err == nil && (len(c.page) > 0 || !c.done) {
+		c.err = c.ctx.Err()
+	}
+
+	if c.err != nil {
+		return false
+	}
+
+	for {
+		for len(c.page) == 0 {
+			if c.done || c.err != nil {
+				return false
+			}
+
+			c.load()
+		}
+
+		item := c.page[0]
+		c.page = c.page[1:]
+
+		_, ts := c.key(item)
+		if c.begin > 0 && ts < c.begin {
+			c.done = true
+			c.page = nil
+			return false
+		}
+
+		if c.end > 0 && ts > c.end {
+			continue
+		}
+
+		c.item = item
+		return true
+	}
+}
+
+// load 加载下一页数据
+func (c *Cursor[T]) load() {
+	if err := c.ctx.Err(); err != nil {
+		c.err = err
+		return
+	}
+
+	page, err := c.fetch(c.ctx, c.after)
+	if err != nil {
+		c.err = err
+		return
+	}
+
+	if len(page) == 0 {
+		c.done = true
+		return
+	}
+
+	full := len(page) >= pageLimit
+	if c.id != nil {
+		if page, err = c.dedupe(page, full); err != nil {
+			c.err = err
+			return
+		}
+
+		if len(page) == 0 {
+			c.done = true
+			return
+		}
+	}
+
+	after, _ := c.key(page[len(page)-1])
+	if after == "" || after == c.after || !full {
+		c.done = true
+	}
+
+	c.after = after
+	c.page = page
+}
+
+// dedupe 去掉上一页已经返回过的数据，并记录本页最后一个时间戳的数据
+func (c *Cursor[T]) dedupe(page []T, full bool) ([]T, error) {
+	_, first := c.key(page[0])
+	_, last := c.key(page[len(page)-1])
+	if full && first == last {
+		return nil, fmt.Errorf("more than %d records at ts %d, narrow the request", pageLimit, last)
+	}
+
+	ret := make([]T, 0, len(page))
+	seen := make(map[string]bool)
+	for _, item := range page {
+		id := c.id(item)
+		_, ts := c.key(item)
+		if ts == c.edge && c.seen[id] {
+			continue
+		}
+
+		ret = append(ret, item)
+		if ts == last {
+			seen[id] = true
+		}
+	}
+
+	c.edge, c.seen = last, seen
+	return ret, nil
+}
+
+// Item 返回当前数据
+func (c *Cursor[T]) Item() T {
+	return c.item
+}
+
+// Err 返回遍历过程中的错误
+func (c *Cursor[T]) Err() error {
+	return c.err
+}
+
+// All 遍历剩余的全部数据
+func (c *Cursor[T]) All() ([]T, error) {
+	var items []T
+	for c.Next() {
+		items = append(items, c.Item())
+	}
+
+	return items, c.Err()
+}
+
+// newTsCursor 返回以时间戳作为游标的Cursor，从end开始向前遍历
+// 多条数据的时间戳相同时可能被分到两页，下一页从上一页最后的时间戳开始请求，按id去掉重复的数据
+func newTsCursor[T any](ctx context.Context, begin, end time.Time, fetch func(ctx context.Context, after string) ([]T, error), ts func(item T) int64, id func(item T) string) *Cursor[T] {
+	cur := &Cursor[T]{
+		ctx:   ctx,
+		fetch: fetch,
+		key: func(item T) (string, int64) {
+			// after参数不包含游标本身
+			t := ts(item)
+			return strconv.FormatInt(t+1, 10), t
+		},
+		id:    id,
+		begin: unixMilli(begin),
+		end:   unixMilli(end),
+	}
+
+	if cur.end > 0 {
+		cur.after = strconv.FormatInt(cur.end+1, 10)
+	}
+
+	return cur
+}
+
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixMilli()
+}
+
+// IterBills 遍历req.Begin到req.End之间的账单流水，见Bills，req中的After、Before和Limit会被忽略
+func (c *RestConfig) IterBills(ctx context.Context, req *BillsRequest) *Cursor[*Bill] {
+	return c.iterBills(ctx, BillsUrl, req)
+}
+
+// IterBillsArchive 遍历req.Begin到req.End之间的账单流水，见BillsArchive，req中的After、Before和Limit会被忽略
+func (c *RestConfig) IterBillsArchive(ctx context.Context, req *BillsRequest) *Cursor[*Bill] {
+	return c.iterBills(ctx, BillsArchiveUrl, req)
+}
+
+func (c *RestConfig) iterBills(ctx context.Context, addr string, req *BillsRequest) *Cursor[*Bill] {
+	r := *req
+	r.Before = ""
+	r.Limit = pageLimit
+	return &Cursor[*Bill]{
+		ctx: ctx,
+		fetch: func(ctx context.Context, after string) ([]*Bill, error) {
+			r.After = after
+			return c.listBills(ctx, addr, &r)
+		},
+		key: func(item *Bill) (string, int64) {
+			return item.BillId, utils.MustParseInt64(item.Ts)
+		},
+		begin: unixMilli(r.Begin),
+		end:   unixMilli(r.End),
+	}
+}
+
+// IterPositionsHistory 遍历begin到end之间更新的历史持仓，req中的After、Before和Limit会被忽略
+func (c *RestConfig) IterPositionsHistory(ctx context.Context, req *PositionsHistoryRequest, begin, end time.Time) *Cursor[*Position] {
+	r := *req
+	r.Before = time.Time{}
+	r.Limit = pageLimit
+	fetch := func(ctx context.Context, after string) ([]*Position, error) {
+		r.After = parseCursor(after)
+		return c.ListPositionsHistoryWithContext(ctx, &r)
+	}
+
+	return newTsCursor(ctx, begin, end, fetch, func(item *Position) int64 {
+		return utils.MustParseInt64(item.UTime)
+	}, func(item *Position) string {
+		return item.PosId + "/" + item.UTime
+	})
+}
+
+// IterInterestAccrued 遍历begin到end之间的计息记录，req中的After、Before和Limit会被忽略
+func (c *RestConfig) IterInterestAccrued(ctx context.Context, req *InterestAccruedRequest, begin, end time.Time) *Cursor[*InterestAccrued] {
+	r := *req
+	r.Before = time.Time{}
+	r.Limit = pageLimit
+	fetch := func(ctx context.Context, after string) ([]*InterestAccrued, error) {
+		r.After = parseCursor(after)
+		return c.ListInterestAccruedWithContext(ctx, &r)
+	}
+
+	return newTsCursor(ctx, begin, end, fetch, func(item *InterestAccrued) int64 {
+		return utils.MustParseInt64(item.Ts)
+	}, func(item *InterestAccrued) string {
+		return item.Type + "/" + item.MgnMode + "/" + item.InstId + "/" + item.Ccy
+	})
+}
+
+// IterHistoryCandles 遍历begin到end之间的历史k线
+func (c *RestConfig) IterHistoryCandles(ctx context.Context, instId, bar string, begin, end time.Time) *Cursor[*Candles] {
+	fetch := func(ctx context.Context, after string) ([]*Candles, error) {
+		return c.HistoryCandlesWithContext(ctx, instId, bar, after, "", strconv.Itoa(pageLimit))
+	}
+
+	return newTsCursor(ctx, begin, end, fetch, func(item *Candles) int64 {
+		return item.Timestamp
+	}, func(item *Candles) string {
+		return strconv.FormatInt(item.Timestamp, 10)
+	})
+}
+
+// IterOrdersPending 遍历begin到end之间创建的未成交订单，req中的After、Before和Limit会被忽略
+func (c *RestConfig) IterOrdersPending(ctx context.Context, req *OrdersPendingRequest, begin, end time.Time) *Cursor[*Order] {
+	r := *req
+	r.Before = ""
+	r.Limit = pageLimit
+	return &Cursor[*Order]{
+		ctx: ctx,
+		fetch: func(ctx context.Context, after string) ([]*Order, error) {
+			r.After = after
+			return c.ListOrdersPendingWithContext(ctx, &r)
+		},
+		key: func(item *Order) (string, int64) {
+			return item.OrdId, utils.MustParseInt64(item.CTime)
+		},
+		begin: unixMilli(begin),
+		end:   unixMilli(end),
+	}
+}
+
+// parseCursor 把时间戳游标转换为请求参数，空字符串返回零值
+func parseCursor(after string) time.Time {
+	if after == "" {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(utils.MustParseInt64(after))
+}
@@ -0,0 +1,127 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// billsPage 模拟账单接口，共有n条账单，billId和ts从n到1递减
+func billsPage(n int) roundTripFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		after := n + 1
+		if v := r.URL.Query().Get("after"); v != "" {
+			after, _ = strconv.Atoi(v)
+		}
+
+		var bills []*Bill
+		for id := after - 1; id >= 1 && len(bills) < pageLimit; id-- {
+			bills = append(bills, &Bill{BillId: strconv.Itoa(id), Ts: strconv.Itoa(id * 1000)})
+		}
+
+		data, _ := json.Marshal(bills)
+		return stubResponse(http.StatusOK, `{"code":"0","msg":"","data":`+string(data)+`}`), nil
+	}
+}
+
+func TestIterBills(t *testing.T) {
+	var calls int
+	page := billsPage(250)
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return page(r)
+	})
+
+	bills, err := c.IterBills(context.Background(), &BillsRequest{}).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bills) != 250 || bills[0].BillId != "250" || bills[249].BillId != "1" || calls != 3 {
+		t.Errorf("got %d bills in %d calls", len(bills), calls)
+	}
+
+	// begin之前的数据不再返回
+	bills, err = c.IterBills(context.Background(), &BillsRequest{Begin: time.UnixMilli(201 * 1000), End: time.UnixMilli(240 * 1000)}).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bills) != 40 || bills[0].BillId != "240" || bills[39].BillId != "201" {
+		t.Errorf("got %d bills", len(bills))
+	}
+}
+
+func TestIterCanceled(t *testing.T) {
+	c := newStubConfig(billsPage(250))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cur := c.IterBillsArchive(ctx, &BillsRequest{})
+
+	var n int
+	for cur.Next() {
+		if n++; n == 10 {
+			cancel()
+		}
+	}
+
+	if cur.Err() != context.Canceled || n != 10 {
+		t.Errorf("n = %d, err = %v", n, cur.Err())
+	}
+}
+
+// interestPage 模拟计息记录接口，每小时有n个币种的记录，时间戳相同
+func interestPage(hours, n int) roundTripFunc {
+	var all []*InterestAccrued
+	for h := hours; h >= 1; h-- {
+		for i := 0; i < n; i++ {
+			all = append(all, &InterestAccrued{Ccy: fmt.Sprintf("C%d", i), Type: "2", Ts: strconv.Itoa(h * 3600000)})
+		}
+	}
+
+	return func(r *http.Request) (*http.Response, error) {
+		after := int64(math.MaxInt64)
+		if v := r.URL.Query().Get("after"); v != "" {
+			after, _ = strconv.ParseInt(v, 10, 64)
+		}
+
+		var items []*InterestAccrued
+		for _, item := range all {
+			if ts, _ := strconv.ParseInt(item.Ts, 10, 64); ts < after && len(items) < pageLimit {
+				items = append(items, item)
+			}
+		}
+
+		data, _ := json.Marshal(items)
+		return stubResponse(http.StatusOK, `{"code":"0","msg":"","data":`+string(data)+`}`), nil
+	}
+}
+
+func TestIterSameTs(t *testing.T) {
+	// 每页的边界都落在同一小时的记录中间
+	c := newStubConfig(interestPage(3, 70))
+	items, err := c.IterInterestAccrued(context.Background(), &InterestAccruedRequest{}, time.Time{}, time.Time{}).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]bool)
+	for _, item := range items {
+		seen[item.Ts+item.Ccy] = true
+	}
+	if len(items) != 210 || len(seen) != 210 {
+		t.Errorf("got %d items, %d unique", len(items), len(seen))
+	}
+
+	// 一页放不下同一时间戳的全部记录时返回错误
+	c = newStubConfig(interestPage(2, 120))
+	if _, err = c.IterInterestAccrued(context.Background(), &InterestAccruedRequest{}, time.Time{}, time.Time{}).All(); err == nil {
+		t.Error("expected error when one ts has more than a page")
+	}
+}
@@ -0,0 +1,383 @@
+package okx
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Order的数值字段，空字符串返回空值，解析失败时返回错误
+
+func (o *Order) PxDecimal() (Decimal, error) {
+	return ParseDecimal(o.Px)
+}
+
+func (o *Order) SzDecimal() (Decimal, error) {
+	return ParseDecimal(o.Sz)
+}
+
+func (o *Order) AccFillSzDecimal() (Decimal, error) {
+	return ParseDecimal(o.AccFillSz)
+}
+
+func (o *Order) FillPxDecimal() (Decimal, error) {
+	return ParseDecimal(o.FillPx)
+}
+
+func (o *Order) FillSzDecimal() (Decimal, error) {
+	return ParseDecimal(o.FillSz)
+}
+
+func (o *Order) AvgPxDecimal() (Decimal, error) {
+	return ParseDecimal(o.AvgPx)
+}
+
+func (o *Order) PnlDecimal() (Decimal, error) {
+	return ParseDecimal(o.Pnl)
+}
+
+func (o *Order) FeeDecimal() (Decimal, error) {
+	return ParseDecimal(o.Fee)
+}
+
+func (o *Order) LeverDecimal() (Decimal, error) {
+	return ParseDecimal(o.Lever)
+}
+
+// Position的数值字段，空字符串返回空值，解析失败时返回错误
+
+func (p *Position) PosDecimal() (Decimal, error) {
+	return ParseDecimal(p.Pos)
+}
+
+func (p *Position) AvailPosDecimal() (Decimal, error) {
+	return ParseDecimal(p.AvailPos)
+}
+
+func (p *Position) AvgPxDecimal() (Decimal, error) {
+	return ParseDecimal(p.AvgPx)
+}
+
+func (p *Position) MarkPxDecimal() (Decimal, error) {
+	return ParseDecimal(p.MarkPx)
+}
+
+func (p *Position) LiqPxDecimal() (Decimal, error) {
+	return ParseDecimal(p.LiqPx)
+}
+
+func (p *Position) MarginDecimal() (Decimal, error) {
+	return ParseDecimal(p.Margin)
+}
+
+func (p *Position) LeverDecimal() (Decimal, error) {
+	return ParseDecimal(p.Lever)
+}
+
+func (p *Position) UplDecimal() (Decimal, error) {
+	return ParseDecimal(p.Upl)
+}
+
+func (p *Position) RealizedPnlDecimal() (Decimal, error) {
+	return ParseDecimal(p.RealizedPnl)
+}
+
+func (p *Position) PnlDecimal() (Decimal, error) {
+	return ParseDecimal(p.Pnl)
+}
+
+func (p *Position) FeeDecimal() (Decimal, error) {
+	return ParseDecimal(p.Fee)
+}
+
+func (p *Position) FundingFeeDecimal() (Decimal, error) {
+	return ParseDecimal(p.FundingFee)
+}
+
+func (p *Position) NotionalUsdDecimal() (Decimal, error) {
+	return ParseDecimal(p.NotionalUsd)
+}
+
+// Ticker的数值字段，空字符串返回空值，解析失败时返回错误
+
+func (t *Ticker) LastDecimal() (Decimal, error) {
+	return ParseDecimal(t.Last)
+}
+
+func (t *Ticker) LastSzDecimal() (Decimal, error) {
+	return ParseDecimal(t.LastSz)
+}
+
+func (t *Ticker) AskPxDecimal() (Decimal, error) {
+	return ParseDecimal(t.AskPx)
+}
+
+func (t *Ticker) AskSzDecimal() (Decimal, error) {
+	return ParseDecimal(t.AskSz)
+}
+
+func (t *Ticker) BidPxDecimal() (Decimal, error) {
+	return ParseDecimal(t.BidPx)
+}
+
+func (t *Ticker) BidSzDecimal() (Decimal, error) {
+	return ParseDecimal(t.BidSz)
+}
+
+func (t *Ticker) Open24hDecimal() (Decimal, error) {
+	return ParseDecimal(t.Open24h)
+}
+
+func (t *Ticker) High24hDecimal() (Decimal, error) {
+	return ParseDecimal(t.High24h)
+}
+
+func (t *Ticker) Low24hDecimal() (Decimal, error) {
+	return ParseDecimal(t.Low24h)
+}
+
+func (t *Ticker) Vol24hDecimal() (Decimal, error) {
+	return ParseDecimal(t.Vol24h)
+}
+
+func (t *Ticker) VolCcy24hDecimal() (Decimal, error) {
+	return ParseDecimal(t.VolCcy24h)
+}
+
+// Balance的数值字段，空字符串返回空值，解析失败时返回错误
+
+func (b *Balance) BalDecimal() (Decimal, error) {
+	return ParseDecimal(b.Bal)
+}
+
+func (b *Balance) AvailBalDecimal() (Decimal, error) {
+	return ParseDecimal(b.AvailBal)
+}
+
+func (b *Balance) FrozenBalDecimal() (Decimal, error) {
+	return ParseDecimal(b.FrozenBal)
+}
+
+// Account的数值字段，空字符串返回空值，解析失败时返回错误
+
+func (a *Account) TotalEqDecimal() (Decimal, error) {
+	return ParseDecimal(a.TotalEq)
+}
+
+func (a *Account) AdjEqDecimal() (Decimal, error) {
+	return ParseDecimal(a.AdjEq)
+}
+
+func (a *Account) IsoEqDecimal() (Decimal, error) {
+	return ParseDecimal(a.IsoEq)
+}
+
+func (a *Account) OrdFrozDecimal() (Decimal, error) {
+	return ParseDecimal(a.OrdFroz)
+}
+
+func (a *Account) ImrDecimal() (Decimal, error) {
+	return ParseDecimal(a.Imr)
+}
+
+func (a *Account) MmrDecimal() (Decimal, error) {
+	return ParseDecimal(a.Mmr)
+}
+
+func (a *Account) MgnRatioDecimal() (Decimal, error) {
+	return ParseDecimal(a.MgnRatio)
+}
+
+func (a *Account) NotionalUsdDecimal() (Decimal, error) {
+	return ParseDecimal(a.NotionalUsd)
+}
+
+// Instrument的数值字段，空字符串返回空值，解析失败时返回错误
+
+func (i *Instrument) CtValDecimal() (Decimal, error) {
+	return ParseDecimal(i.CtVal)
+}
+
+func (i *Instrument) CtMultDecimal() (Decimal, error) {
+	return ParseDecimal(i.CtMult)
+}
+
+func (i *Instrument) LotSzDecimal() (Decimal, error) {
+	return ParseDecimal(i.LotSz)
+}
+
+func (i *Instrument) MinSzDecimal() (Decimal, error) {
+	return ParseDecimal(i.MinSz)
+}
+
+func (i *Instrument) TickSzDecimal() (Decimal, error) {
+	return ParseDecimal(i.TickSz)
+}
+
+// Notional 按合约面值计算以px成交sz的名义价值
+// 币币为px*sz，正向合约为px*sz*ctVal*ctMult，以计价货币为单位，反向合约为sz*ctVal*ctMult，以美元为单位
+func (i *Instrument) Notional(px, sz Decimal) (Decimal, error) {
+	if i.CtVal == "" {
+		return px.Mul(sz), nil
+	}
+
+	ctVal, err := i.CtValDecimal()
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	ctMult, err := i.CtMultDecimal()
+	if err != nil {
+		return Decimal{}, err
+	}
+	if ctMult.IsEmpty() {
+		ctMult = NewDecimal(1, 0)
+	}
+
+	notional := sz.Mul(ctVal).Mul(ctMult)
+	if i.CtType == "inverse" {
+		return notional, nil
+	}
+
+	return notional.Mul(px), nil
+}
+
+// FundingRate的数值字段，空字符串返回空值，解析失败时返回错误
+
+func (f *FundingRate) FundingRateDecimal() (Decimal, error) {
+	return ParseDecimal(f.FundingRate)
+}
+
+func (f *FundingRate) NextFundingRateDecimal() (Decimal, error) {
+	return ParseDecimal(f.NextFundingRate)
+}
+
+// MarkPrice的数值字段，空字符串返回空值，解析失败时返回错误
+
+func (m *MarkPrice) MarkPxDecimal() (Decimal, error) {
+	return ParseDecimal(m.MarkPx)
+}
+
+// IndexTicker的数值字段，空字符串返回空值，解析失败时返回错误
+
+func (t *IndexTicker) IdxPxDecimal() (Decimal, error) {
+	return ParseDecimal(t.IdxPx)
+}
+
+// OpenInterest的数值字段，空字符串返回空值，解析失败时返回错误
+
+func (o *OpenInterest) OiDecimal() (Decimal, error) {
+	return ParseDecimal(o.Oi)
+}
+
+func (o *OpenInterest) OiCcyDecimal() (Decimal, error) {
+	return ParseDecimal(o.OiCcy)
+}
+
+// Trade的数值字段，空字符串返回空值，解析失败时返回错误
+
+func (t *Trade) PxDecimal() (Decimal, error) {
+	return ParseDecimal(t.Px)
+}
+
+func (t *Trade) SzDecimal() (Decimal, error) {
+	return ParseDecimal(t.Sz)
+}
+
+// parseCandles 解析k线数据，格式为[ts,o,h,l,c,vol,volCcy,volCcyQuote,confirm]
+func parseCandles(items [][]string) ([]*Candles, error) {
+	var ret []*Candles
+	for _, item := range items {
+		candle, err := parseCandle(item)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, candle)
+	}
+
+	return ret, nil
+}
+
+func parseCandle(item []string) (*Candles, error) {
+	if len(item) < 9 {
+		return nil, fmt.Errorf("invalid candle: %v", item)
+	}
+
+	ts, err := strconv.ParseInt(item[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid candle ts: %q", item[0])
+	}
+
+	confirm, err := strconv.ParseInt(item[8], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid candle confirm: %q", item[8])
+	}
+
+	var values [7]Decimal
+	for i := range values {
+		if values[i], err = ParseDecimal(item[i+1]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Candles{
+		Timestamp:   ts,
+		Open:        values[0].Float64(),
+		High:        values[1].Float64(),
+		Low:         values[2].Float64(),
+		Close:       values[3].Float64(),
+		Confirm:     confirm,
+		OpenPx:      values[0],
+		HighPx:      values[1],
+		LowPx:       values[2],
+		ClosePx:     values[3],
+		Vol:         values[4],
+		VolCcy:      values[5],
+		VolCcyQuote: values[6],
+	}, nil
+}
+
+// parseBookOrders 解析深度数据，格式为[px,sz,0,ordCount]
+func parseBookOrders(items [][]string) ([]*BookOrder, error) {
+	var ret []*BookOrder
+	for _, item := range items {
+		order, err := parseBookOrder(item)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, order)
+	}
+
+	return ret, nil
+}
+
+func parseBookOrder(item []string) (*BookOrder, error) {
+	if len(item) < 4 {
+		return nil, fmt.Errorf("invalid book level: %v", item)
+	}
+
+	px, err := ParseDecimal(item[0])
+	if err != nil {
+		return nil, err
+	}
+
+	sz, err := ParseDecimal(item[1])
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := strconv.ParseInt(item[3], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid book order count: %q", item[3])
+	}
+
+	notional := px.Mul(sz)
+	return &BookOrder{
+		Price:         px.Float64(),
+		Amount:        sz.Float64(),
+		Value:         notional.Round(0).Float64(),
+		OrderQuantity: count,
+		Px:            px,
+		Sz:            sz,
+		Notional:      notional,
+	}, nil
+}
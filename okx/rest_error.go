@@ -0,0 +1,200 @@
+package okx
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"strings"
+)
+
+// 常见错误码
+const (
+	CodeSuccess              = "0"
+	CodeOperationFailed      = "1" // 批量操作全部失败，具体原因见sCode
+	CodeBatchPartial         = "2" // 批量操作部分成功，具体原因见sCode
+	CodeServiceBusy          = "50001"
+	CodeRequestTimeout       = "50004"
+	CodeRateLimited          = "50011" // 用户请求频率过快
+	CodeSystemBusy           = "50013"
+	CodeSystemError          = "50026"
+	CodeSubRateLimited       = "50061" // 子账户请求频率过快
+	CodeTimestampExpired     = "50102" // 请求时间戳过期
+	CodeInvalidTimestamp     = "50112" // 无效的请求时间戳
+	CodeInsufficientBalance  = "51008" // 余额或保证金不足
+	CodeAvailBalanceZero     = "51127" // 可用余额为0
+	CodeInsufficientTradeBal = "51131" // 交易账户余额不足
+	CodeInsufficientFundBal  = "58350" // 资金账户余额不足
+	CodeInsufficientAcctBal  = "59200" // 账户余额不足
+)
+
+// APIError OKX接口返回的错误
+type APIError struct {
+	Code       string       // 错误码
+	Msg        string       // 错误信息
+	HTTPStatus int          // http状态码
+	Method     string       // 请求方法
+	Path       string       // 请求路径
+	Items      []*ItemError // 批量接口中每一项的失败原因
+}
+
+// BatchError 分多次请求的批量操作中途有一次请求整体失败，之后的请求不再发送
+type BatchError struct {
+	Err     error     // 整体失败的原因
+	Start   int       // 失败的请求在reqs中的开始位置，之前的结果已经返回
+	Partial *APIError // 之前的请求中单项失败的原因，Index为在reqs中的位置，没有时为nil
+}
+
+func (e *BatchError) Error() string {
+	msg := fmt.Sprintf("okx: batch failed from index %d: %v", e.Start, e.Err)
+	if e.Partial != nil {
+		msg += "; earlier " + e.Partial.Error()
+	}
+
+	return msg
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// ItemError 批量接口中单项的失败原因，对应返回数据中的sCode和sMsg
+type ItemError struct {
+	Index   int    // 在请求数据中的位置
+	OrdId   string `json:"ordId"`
+	ClOrdId string `json:"clOrdId"`
+	AlgoId  string `json:"algoId"`
+	SCode   string `json:"sCode"`
+	SMsg    string `json:"sMsg"`
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "okx: %s %s code: %s, msg: %s", e.Method, e.Path, e.Code, e.Msg)
+	if e.HTTPStatus != 0 && e.HTTPStatus != http.StatusOK {
+		fmt.Fprintf(&b, ", status: %d", e.HTTPStatus)
+	}
+
+	for _, item := range e.Items {
+		fmt.Fprintf(&b, ", [%d] sCode: %s, sMsg: %s", item.Index, item.SCode, item.SMsg)
+	}
+
+	return b.String()
+}
+
+// codes 返回错误码及所有单项错误码
+func (e *APIError) codes() []string {
+	codes := []string{e.Code}
+	for _, item := range e.Items {
+		codes = append(codes, item.SCode)
+	}
+
+	return codes
+}
+
+func (e *APIError) hasCode(codes ...string) bool {
+	for _, c := range e.codes() {
+		for _, code := range codes {
+			if c == code {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// newAPIError 根据响应构造APIError，Data中包含sCode的项会被解析为ItemError
+func newAPIError(resp *ResponseBean, status int, method, path string) *APIError {
+	e := &APIError{
+		Code:       resp.Code,
+		Msg:        resp.Msg,
+		HTTPStatus: status,
+		Method:     method,
+		Path:       path,
+	}
+
+	if len(resp.Data) == 0 {
+		return e
+	}
+
+	bytes, err := json.Marshal(resp.Data)
+	if err != nil {
+		return e
+	}
+
+	var items []*ItemError
+	if err = json.Unmarshal(bytes, &items); err != nil {
+		return e
+	}
+
+	for i, item := range items {
+		if item.SCode == "" || item.SCode == CodeSuccess {
+			continue
+		}
+
+		item.Index = i
+		e.Items = append(e.Items, item)
+	}
+
+	if e.Msg == "" && len(e.Items) > 0 {
+		e.Msg = e.Items[0].SMsg
+	}
+
+	return e
+}
+
+// AsAPIError 从err中取出*APIError
+func AsAPIError(err error) (*APIError, bool) {
+	var e *APIError
+	if errors.As(err, &e) {
+		return e, true
+	}
+
+	return nil, false
+}
+
+// IsRateLimited 是否触发了限速，包括交易所限速和本地限速器
+func IsRateLimited(err error) bool {
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+
+	if e, ok := AsAPIError(err); ok {
+		return e.HTTPStatus == http.StatusTooManyRequests || e.hasCode(CodeRateLimited, CodeSubRateLimited)
+	}
+
+	return false
+}
+
+// IsInsufficientBalance 是否余额或保证金不足
+func IsInsufficientBalance(err error) bool {
+	if e, ok := AsAPIError(err); ok {
+		return e.hasCode(CodeInsufficientBalance, CodeAvailBalanceZero, CodeInsufficientTradeBal, CodeInsufficientFundBal, CodeInsufficientAcctBal)
+	}
+
+	return false
+}
+
+// IsTimestampExpired 是否请求时间戳过期或无效，通常由本地时间误差导致
+func IsTimestampExpired(err error) bool {
+	if e, ok := AsAPIError(err); ok {
+		return e.hasCode(CodeTimestampExpired, CodeInvalidTimestamp)
+	}
+
+	return false
+}
+
+// IsRetryable 是否为可重试的错误：限速、服务端繁忙或5xx
+func IsRetryable(err error) bool {
+	if IsRateLimited(err) {
+		return true
+	}
+
+	e, ok := AsAPIError(err)
+	if !ok {
+		return false
+	}
+
+	return e.HTTPStatus >= http.StatusInternalServerError || e.hasCode(CodeServiceBusy, CodeRequestTimeout, CodeSystemBusy, CodeSystemError)
+}
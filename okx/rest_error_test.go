@@ -0,0 +1,73 @@
+package okx
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusOK, `{"code":"1","msg":"","data":[{"clOrdId":"a1","ordId":"","sCode":"51008","sMsg":"Order failed. Insufficient balance"}]}`), nil
+	})
+
+	_, err := c.MakeOrder("BTC-USDT", Cash, "", "a1", Buy, Market, "", "1", false, "", "", false, nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	var apiErr *APIError
+	if !errors.As(fmt.Errorf("wrap: %w", err), &apiErr) {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+
+	if apiErr.Code != CodeOperationFailed || apiErr.Path != OrderUrl || apiErr.Method != http.MethodPost {
+		t.Errorf("unexpected error: %+v", apiErr)
+	}
+
+	if len(apiErr.Items) != 1 || apiErr.Items[0].ClOrdId != "a1" || apiErr.Items[0].SCode != CodeInsufficientBalance {
+		t.Errorf("unexpected items: %+v", apiErr.Items)
+	}
+
+	if !IsInsufficientBalance(err) || IsRetryable(err) || IsRateLimited(err) {
+		t.Errorf("wrong classification for %v", err)
+	}
+}
+
+func TestBatchOrdersPartial(t *testing.T) {
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusOK, `{"code":"2","msg":"","data":[{"ordId":"1","sCode":"0","sMsg":""},{"ordId":"","sCode":"50011","sMsg":"Too Many Requests"}]}`), nil
+	})
+
+	orders, err := c.BatchOrders([]*Order{{InstId: "BTC-USDT"}, {InstId: "ETH-USDT"}})
+	if len(orders) != 2 || orders[0].OrdId != "1" {
+		t.Errorf("partial results should be returned, got %+v", orders)
+	}
+
+	apiErr, ok := AsAPIError(err)
+	if !ok || len(apiErr.Items) != 1 || apiErr.Items[0].Index != 1 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !IsRateLimited(err) || !IsRetryable(err) {
+		t.Errorf("wrong classification for %v", err)
+	}
+}
+
+func TestHTTPStatusError(t *testing.T) {
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusBadGateway, `<html>bad gateway</html>`), nil
+	})
+	c.SetRetryPolicy(NoRetryPolicy)
+
+	_, err := c.Ticker("BTC-USDT")
+	apiErr, ok := AsAPIError(err)
+	if !ok || apiErr.HTTPStatus != http.StatusBadGateway || apiErr.Path != TickerUrl {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !IsRetryable(err) || IsTimestampExpired(err) {
+		t.Errorf("wrong classification for %v", err)
+	}
+}
@@ -0,0 +1,262 @@
+package okx
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"math"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited 本地限速器在RateLimitFailFast策略下拒绝请求时返回
+var ErrRateLimited = errors.New("okx: local rate limit exceeded")
+
+// RateLimitPolicy 触发本地限速时的处理策略
+type RateLimitPolicy int
+
+const (
+	RateLimitWait     RateLimitPolicy = iota // 阻塞等待直到可以发送
+	RateLimitFailFast                        // 立即返回ErrRateLimited
+	RateLimitDisabled                        // 不做本地限速
+)
+
+// RateLimit 接口限速规则
+type RateLimit struct {
+	Count    int           // 时间窗口内允许的请求数
+	Per      time.Duration // 时间窗口
+	ByInstId bool          // 是否按产品id分别限速
+}
+
+// defaultRateLimits OKX文档中各接口的限速规则
+var defaultRateLimits = map[string]RateLimit{
+	TimeUrl:        {Count: 10, Per: 2 * time.Second},
+	InstrumentsUrl: {Count: 20, Per: 2 * time.Second},
+	FundingRateUrl: {Count: 20, Per: 2 * time.Second, ByInstId: true},
+
+	AssetValuationUrl: {Count: 1, Per: time.Second},
+	AssetBalancesUrl:  {Count: 6, Per: time.Second},
+
+	PositionsUrl:        {Count: 10, Per: 2 * time.Second},
+	PositionsHistoryUrl: {Count: 10, Per: 2 * time.Second},
+	SetPosModeUrl:       {Count: 5, Per: 2 * time.Second},
+	SetLeverageUrl:      {Count: 20, Per: 2 * time.Second},
+	BalanceUrl:          {Count: 10, Per: 2 * time.Second},
+	MaxSizeUrl:          {Count: 20, Per: 2 * time.Second},
+	AccountConfigUrl:    {Count: 5, Per: 2 * time.Second},
+	TradeFeeUrl:         {Count: 5, Per: 2 * time.Second},
+	InterestLimitsUrl:   {Count: 5, Per: 2 * time.Second},
+	BillsUrl:            {Count: 5, Per: time.Second},
+	BillsArchiveUrl:     {Count: 5, Per: 2 * time.Second},
+	InterestAccruedUrl:  {Count: 5, Per: 2 * time.Second},
+	InterestRateUrl:     {Count: 5, Per: 2 * time.Second},
+
+	TickerUrl:     {Count: 20, Per: 2 * time.Second},
+	TickersUrl:    {Count: 20, Per: 2 * time.Second},
+	CandlesUrl:    {Count: 40, Per: 2 * time.Second},
+	HisCandlesUrl: {Count: 20, Per: 2 * time.Second},
+	BooksUrl:      {Count: 40, Per: 2 * time.Second},
+
+	OrderUrl:             {Count: 60, Per: 2 * time.Second, ByInstId: true},
+	BatchOrdersUrl:       {Count: 300, Per: 2 * time.Second, ByInstId: true},
+	ClosePositionUrl:     {Count: 20, Per: 2 * time.Second, ByInstId: true},
+	CancelOrderUrl:       {Count: 60, Per: 2 * time.Second, ByInstId: true},
+	CancelBatchOrdersUrl: {Count: 300, Per: 2 * time.Second, ByInstId: true},
+	MassCancelUrl:        {Count: 5, Per: 2 * time.Second},
+	CancelAllAfterUrl:    {Count: 1, Per: time.Second},
+	AmendOrderUrl:        {Count: 60, Per: 2 * time.Second, ByInstId: true},
+	AmendBatchOrdersUrl:  {Count: 300, Per: 2 * time.Second, ByInstId: true},
+	OrdersPendingUrl:     {Count: 60, Per: 2 * time.Second},
+	PostOrderAlgo:        {Count: 20, Per: 2 * time.Second},
+	PostCancelOrderAlgos: {Count: 20, Per: 2 * time.Second},
+	AmendAlgosUrl:        {Count: 20, Per: 2 * time.Second},
+	OrdersAlgoPendingUrl: {Count: 20, Per: 2 * time.Second},
+	OrdersAlgoHistoryUrl: {Count: 20, Per: 2 * time.Second},
+}
+
+// RateLimiter 按接口和产品id限速的令牌桶，可在多个goroutine及多个RestConfig间共享
+type RateLimiter struct {
+	mu      sync.Mutex
+	policy  RateLimitPolicy
+	limits  map[string]RateLimit
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	size   float64
+	rate   float64 // 每秒恢复的令牌数
+	last   time.Time
+}
+
+func NewRateLimiter(policy RateLimitPolicy) *RateLimiter {
+	limits := make(map[string]RateLimit, len(defaultRateLimits))
+	for k, v := range defaultRateLimits {
+		limits[k] = v
+	}
+
+	return &RateLimiter{
+		policy:  policy,
+		limits:  limits,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// SetLimit 修改指定接口的限速规则，Count为0时取消该接口的限速
+func (l *RateLimiter) SetLimit(endpoint string, limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limit.Count <= 0 || limit.Per <= 0 {
+		delete(l.limits, endpoint)
+	} else {
+		l.limits[endpoint] = limit
+	}
+
+	prefix := endpoint + "|"
+	for k := range l.buckets {
+		if strings.HasPrefix(k, prefix) {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// Wait 为一次请求获取令牌，instIds中每出现一次消耗对应产品的一个令牌
+func (l *RateLimiter) Wait(ctx context.Context, endpoint string, instIds ...string) error {
+	if l.policy == RateLimitDisabled {
+		return nil
+	}
+
+	for {
+		delay, ok := l.reserve(endpoint, instIds)
+		if !ok {
+			return nil
+		}
+
+		if delay <= 0 {
+			return nil
+		}
+
+		if l.policy == RateLimitFailFast {
+			return ErrRateLimited
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve 尝试一次性扣除所需的全部令牌，不足时返回需要等待的时间
+func (l *RateLimiter) reserve(endpoint string, instIds []string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit, ok := l.limits[endpoint]
+	if !ok {
+		return 0, false
+	}
+
+	weights := map[string]float64{"": 1}
+	if limit.ByInstId && len(instIds) > 0 {
+		weights = make(map[string]float64)
+		for _, instId := range instIds {
+			weights[instId]++
+		}
+	}
+
+	now := time.Now()
+	var delay time.Duration
+	for instId, n := range weights {
+		b := l.bucket(endpoint, instId, limit)
+		b.refill(now)
+		if d := b.wait(n); d > delay {
+			delay = d
+		}
+	}
+
+	if delay > 0 {
+		return delay, true
+	}
+
+	for instId, n := range weights {
+		l.bucket(endpoint, instId, limit).tokens -= n
+	}
+
+	return 0, true
+}
+
+func (l *RateLimiter) bucket(endpoint, instId string, limit RateLimit) *bucket {
+	key := endpoint + "|" + instId
+	b, ok := l.buckets[key]
+	if !ok {
+		b = newBucket(limit.Count, limit.Per)
+		l.buckets[key] = b
+	}
+
+	return b
+}
+
+func newBucket(count int, per time.Duration) *bucket {
+	return &bucket{
+		tokens: float64(count),
+		size:   float64(count),
+		rate:   float64(count) / per.Seconds(),
+		last:   time.Now(),
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if !now.After(b.last) {
+		return
+	}
+
+	b.tokens = math.Min(b.size, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// wait 返回获取n个令牌还需要等待的时间
+func (b *bucket) wait(n float64) time.Duration {
+	n = math.Min(n, b.size)
+	if b.tokens >= n {
+		return 0
+	}
+
+	return time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+}
+
+// requestInstIds 从请求参数中取出产品id，用于按产品限速
+func requestInstIds(msg interface{}, addr string) []string {
+	var instIds []string
+	if _, query, ok := strings.Cut(addr, "?"); ok {
+		if values, err := url.ParseQuery(query); err == nil && values.Get("instId") != "" {
+			instIds = append(instIds, values.Get("instId"))
+		}
+	}
+
+	switch v := msg.(type) {
+	case Params:
+		if instId, ok := v["instId"].(string); ok && instId != "" {
+			instIds = append(instIds, instId)
+		}
+	case *PlaceOrderRequest:
+		instIds = append(instIds, v.InstId)
+	case []*Order:
+		for _, order := range v {
+			instIds = append(instIds, order.InstId)
+		}
+	case *AmendOrderRequest:
+		instIds = append(instIds, v.InstId)
+	case []*AmendOrderRequest:
+		for _, req := range v {
+			instIds = append(instIds, req.InstId)
+		}
+	case []*CancelOrderRequest:
+		for _, req := range v {
+			instIds = append(instIds, req.InstId)
+		}
+	}
+
+	return instIds
+}
@@ -0,0 +1,82 @@
+package okx
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterFailFast(t *testing.T) {
+	l := NewRateLimiter(RateLimitFailFast)
+	l.SetLimit(OrderUrl, RateLimit{Count: 2, Per: time.Second, ByInstId: true})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := l.Wait(ctx, OrderUrl, "BTC-USDT"); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+
+	if err := l.Wait(ctx, OrderUrl, "BTC-USDT"); !IsRateLimited(err) {
+		t.Errorf("expected rate limit error, got %v", err)
+	}
+
+	// 不同产品使用独立的令牌桶
+	if err := l.Wait(ctx, OrderUrl, "ETH-USDT"); err != nil {
+		t.Errorf("other instId should not be limited: %v", err)
+	}
+
+	// 未配置的接口不限速
+	if err := l.Wait(ctx, "/api/v5/unknown"); err != nil {
+		t.Errorf("unknown endpoint should not be limited: %v", err)
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	l := NewRateLimiter(RateLimitWait)
+	l.SetLimit(CandlesUrl, RateLimit{Count: 1, Per: 100 * time.Millisecond})
+
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx, CandlesUrl); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("limiter did not block, elapsed %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, CandlesUrl); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRestConfigRateLimiter(t *testing.T) {
+	var calls int
+	l := NewRateLimiter(RateLimitFailFast)
+	l.SetLimit(BatchOrdersUrl, RateLimit{Count: 2, Per: time.Minute, ByInstId: true})
+
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return stubResponse(http.StatusOK, `{"code":"0","msg":"","data":[]}`), nil
+	})
+	c.SetRateLimiter(l)
+
+	orders := []*Order{{InstId: "BTC-USDT"}, {InstId: "BTC-USDT"}}
+	if _, err := c.BatchOrders(orders); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.BatchOrders(orders[:1]); !IsRateLimited(err) {
+		t.Errorf("expected rate limit error, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
@@ -0,0 +1,497 @@
+package okx
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BillsRequest 账单流水查询参数
+type BillsRequest struct {
+	InstType string    `url:"instType"`
+	Ccy      string    `url:"ccy"`
+	MgnMode  string    `url:"mgnMode"` // 仅支持isolated和cross
+	CtType   string    `url:"ctType"`  // linear或inverse，仅适用于交割/永续
+	Type     string    `url:"type"`    // 账单类型
+	SubType  string    `url:"subType"` // 账单子类型
+	After    string    `url:"after"`   // 返回此billId之前（更旧）的数据
+	Before   string    `url:"before"`  // 返回此billId之后（更新）的数据
+	Begin    time.Time `url:"begin"`
+	End      time.Time `url:"end"`
+	Limit    int       `url:"limit"` // 最大100，默认100
+}
+
+func (r *BillsRequest) Validate() error {
+	return firstError(
+		checkOneOf("instType", r.InstType, SPOT, MARGIN, SWAP, FUTURES, OPTION),
+		checkOneOf("mgnMode", r.MgnMode, Isolated, Cross),
+		checkOneOf("ctType", r.CtType, Linear, Inverse),
+		checkRange(r.Begin, r.End),
+		checkLimit(r.Limit, pageLimit),
+	)
+}
+
+// OrdersPendingRequest 未成交订单查询参数
+type OrdersPendingRequest struct {
+	InstType   string `url:"instType"`
+	Uly        string `url:"uly"`
+	InstFamily string `url:"instFamily"`
+	InstId     string `url:"instId"`
+	OrdType    string `url:"ordType"`
+	State      string `url:"state"`  // live或partially_filled
+	After      string `url:"after"`  // 返回此ordId之前（更旧）的数据
+	Before     string `url:"before"` // 返回此ordId之后（更新）的数据
+	Limit      int    `url:"limit"`  // 最大100，默认100
+}
+
+func (r *OrdersPendingRequest) Validate() error {
+	return firstError(
+		checkOneOf("instType", r.InstType, SPOT, MARGIN, SWAP, FUTURES, OPTION),
+		checkOneOf("state", r.State, Live, PartiallyFilled),
+		checkLimit(r.Limit, pageLimit),
+	)
+}
+
+// PositionsHistoryRequest 历史持仓查询参数
+type PositionsHistoryRequest struct {
+	InstType string    `url:"instType"`
+	InstId   string    `url:"instId"`
+	MgnMode  string    `url:"mgnMode"`
+	Type     string    `url:"type"` // 平仓类型
+	PosId    string    `url:"posId"`
+	After    time.Time `url:"after"`  // 返回此更新时间之前（更旧）的数据
+	Before   time.Time `url:"before"` // 返回此更新时间之后（更新）的数据
+	Limit    int       `url:"limit"`  // 最大100，默认100
+}
+
+func (r *PositionsHistoryRequest) Validate() error {
+	return firstError(
+		checkOneOf("instType", r.InstType, MARGIN, SWAP, FUTURES, OPTION),
+		checkOneOf("mgnMode", r.MgnMode, Isolated, Cross),
+		checkLimit(r.Limit, pageLimit),
+	)
+}
+
+// InterestAccruedRequest 计息记录查询参数
+type InterestAccruedRequest struct {
+	Type    string    `url:"type"` // 1：尊享借币 2：市场借币
+	Ccy     string    `url:"ccy"`
+	InstId  string    `url:"instId"`
+	MgnMode string    `url:"mgnMode"`
+	After   time.Time `url:"after"`  // 返回此时间之前（更旧）的数据
+	Before  time.Time `url:"before"` // 返回此时间之后（更新）的数据
+	Limit   int       `url:"limit"`  // 最大100，默认100
+}
+
+func (r *InterestAccruedRequest) Validate() error {
+	return firstError(
+		checkOneOf("type", r.Type, "1", "2"),
+		checkOneOf("mgnMode", r.MgnMode, Isolated, Cross),
+		checkLimit(r.Limit, pageLimit),
+	)
+}
+
+// PlaceOrderRequest 下单参数
+type PlaceOrderRequest struct {
+	InstId         string     `json:"instId"`
+	TdMode         string     `json:"tdMode"`
+	Ccy            string     `json:"ccy,omitempty"`     // 保证金币种，仅适用于单币种保证金模式下的全仓币币杠杆订单
+	ClOrdId        string     `json:"clOrdId,omitempty"` // 客户自定义订单ID
+	Tag            string     `json:"tag,omitempty"`
+	Side           string     `json:"side"`
+	PosSide        string     `json:"posSide,omitempty"` // 开平仓模式下必填
+	OrdType        string     `json:"ordType"`
+	Sz             string     `json:"sz"`
+	Px             string     `json:"px,omitempty"` // 限价类订单必填
+	ReduceOnly     bool       `json:"reduceOnly,omitempty"`
+	TgtCcy         string     `json:"tgtCcy,omitempty"` // 币币市价单委托数量的类型
+	BanAmend       bool       `json:"banAmend,omitempty"`
+	StpMode        string     `json:"stpMode,omitempty"` // 自成交保护模式
+	AttachAlgoOrds []*Trigger `json:"attachAlgoOrds,omitempty"`
+}
+
+func (r *PlaceOrderRequest) Validate() error {
+	return firstError(
+		checkRequired("instId", r.InstId),
+		checkRequired("tdMode", r.TdMode),
+		checkOneOf("tdMode", r.TdMode, Isolated, Cross, Cash, SpotIsolated),
+		checkRequired("side", r.Side),
+		checkOneOf("side", r.Side, Buy, Sell),
+		checkOneOf("posSide", r.PosSide, MakeLong, MakeShort, Net),
+		checkRequired("ordType", r.OrdType),
+		checkRequired("sz", r.Sz),
+		checkOneOf("tgtCcy", r.TgtCcy, BaseCcy, QuoteCcy),
+		r.checkPx(),
+	)
+}
+
+func (r *PlaceOrderRequest) checkPx() error {
+	switch r.OrdType {
+	case Limit, PostOnly, Fok, Ioc:
+		return checkRequired("px", r.Px)
+	}
+
+	return nil
+}
+
+// CancelOrderRequest 撤单参数，ordId和clOrdId必须传一个
+type CancelOrderRequest struct {
+	InstId  string `json:"instId"`
+	OrdId   string `json:"ordId,omitempty"`
+	ClOrdId string `json:"clOrdId,omitempty"`
+}
+
+func (r *CancelOrderRequest) Validate() error {
+	return firstError(
+		checkRequired("instId", r.InstId),
+		checkRequired("ordId or clOrdId", r.OrdId+r.ClOrdId),
+	)
+}
+
+// AmendOrderRequest 修改订单参数，ordId和clOrdId必须传一个
+type AmendOrderRequest struct {
+	InstId         string          `json:"instId"`
+	CxlOnFail      bool            `json:"cxlOnFail,omitempty"` // 修改失败时是否自动撤单
+	OrdId          string          `json:"ordId,omitempty"`
+	ClOrdId        string          `json:"clOrdId,omitempty"`
+	ReqId          string          `json:"reqId,omitempty"` // 用户自定义修改事件ID
+	NewSz          string          `json:"newSz,omitempty"` // 修改后的数量，包含已成交数量
+	NewPx          string          `json:"newPx,omitempty"`
+	AttachAlgoOrds []*AmendTrigger `json:"attachAlgoOrds,omitempty"` // 修改附带的止盈止损
+}
+
+func (r *AmendOrderRequest) Validate() error {
+	err := firstError(
+		checkRequired("instId", r.InstId),
+		checkRequired("ordId or clOrdId", r.OrdId+r.ClOrdId),
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(r.AttachAlgoOrds) == 0 {
+		return checkRequired("newSz or newPx", r.NewSz+r.NewPx)
+	}
+
+	for _, t := range r.AttachAlgoOrds {
+		if err := checkRequired("attachAlgoId or attachAlgoClOrdId", t.AttachAlgoId+t.AttachAlgoClOrdId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AmendTrigger 修改订单附带的止盈止损，为没有止盈止损的订单新增时传attachAlgoClOrdId
+type AmendTrigger struct {
+	AttachAlgoId       string `json:"attachAlgoId,omitempty"`
+	AttachAlgoClOrdId  string `json:"attachAlgoClOrdId,omitempty"`
+	NewTpTriggerPx     string `json:"newTpTriggerPx,omitempty"` // 止盈触发价，为0时删除止盈
+	NewTpTriggerPxType string `json:"newTpTriggerPxType,omitempty"`
+	NewTpOrdPx         string `json:"newTpOrdPx,omitempty"`     // 止盈委托价，为-1时执行市价止盈
+	NewSlTriggerPx     string `json:"newSlTriggerPx,omitempty"` // 止损触发价，为0时删除止损
+	NewSlTriggerPxType string `json:"newSlTriggerPxType,omitempty"`
+	NewSlOrdPx         string `json:"newSlOrdPx,omitempty"` // 止损委托价，为-1时执行市价止损
+	Sz                 string `json:"sz,omitempty"`         // 分批止盈止损的数量
+}
+
+// AlgoOrderRequest 策略委托的公共参数
+type AlgoOrderRequest struct {
+	InstId        string `json:"instId"`
+	TdMode        string `json:"tdMode"`
+	Ccy           string `json:"ccy,omitempty"`
+	Side          string `json:"side"`
+	PosSide       string `json:"posSide,omitempty"` // 开平仓模式下必填
+	OrdType       string `json:"ordType"`           // 由下单方法设置
+	Sz            string `json:"sz,omitempty"`
+	Tag           string `json:"tag,omitempty"`
+	TgtCcy        string `json:"tgtCcy,omitempty"`
+	AlgoClOrdId   string `json:"algoClOrdId,omitempty"` // 客户自定义策略订单ID
+	ReduceOnly    bool   `json:"reduceOnly,omitempty"`
+	CloseFraction string `json:"closeFraction,omitempty"` // 平仓比例，1表示全部平仓，仅适用于止盈止损，与sz二选一
+}
+
+func (r *AlgoOrderRequest) Validate() error {
+	err := firstError(
+		checkRequired("instId", r.InstId),
+		checkRequired("tdMode", r.TdMode),
+		checkOneOf("tdMode", r.TdMode, Isolated, Cross, Cash, SpotIsolated),
+		checkRequired("side", r.Side),
+		checkOneOf("side", r.Side, Buy, Sell),
+		checkOneOf("posSide", r.PosSide, MakeLong, MakeShort, Net),
+		checkOneOf("tgtCcy", r.TgtCcy, BaseCcy, QuoteCcy),
+	)
+	if err != nil {
+		return err
+	}
+
+	if r.OrdType == Conditional || r.OrdType == Oco {
+		return checkRequired("sz or closeFraction", r.Sz+r.CloseFraction)
+	}
+
+	return checkRequired("sz", r.Sz)
+}
+
+// TpSlOrderRequest 单向止盈止损和双向止盈止损参数，委托价为-1时执行市价
+type TpSlOrderRequest struct {
+	AlgoOrderRequest
+	TpTriggerPx     string `json:"tpTriggerPx,omitempty"`
+	TpTriggerPxType string `json:"tpTriggerPxType,omitempty"` // last、index或mark，默认last
+	TpOrdPx         string `json:"tpOrdPx,omitempty"`
+	SlTriggerPx     string `json:"slTriggerPx,omitempty"`
+	SlTriggerPxType string `json:"slTriggerPxType,omitempty"`
+	SlOrdPx         string `json:"slOrdPx,omitempty"`
+	CxlOnClosePos   bool   `json:"cxlOnClosePos,omitempty"` // 平仓后是否撤销止盈止损
+}
+
+func (r *TpSlOrderRequest) Validate() error {
+	if err := r.AlgoOrderRequest.Validate(); err != nil {
+		return err
+	}
+
+	tp := firstError(checkRequired("tpTriggerPx", r.TpTriggerPx), checkRequired("tpOrdPx", r.TpOrdPx))
+	sl := firstError(checkRequired("slTriggerPx", r.SlTriggerPx), checkRequired("slOrdPx", r.SlOrdPx))
+	if r.OrdType == Oco {
+		return firstError(tp, sl)
+	}
+
+	if tp != nil && sl != nil {
+		return fmt.Errorf("tp or sl is required")
+	}
+
+	return nil
+}
+
+// TriggerOrderRequest 计划委托参数，触发后以orderPx下单，orderPx为-1时执行市价
+type TriggerOrderRequest struct {
+	AlgoOrderRequest
+	TriggerPx      string     `json:"triggerPx"`
+	TriggerPxType  string     `json:"triggerPxType,omitempty"`
+	OrderPx        string     `json:"orderPx"`
+	AttachAlgoOrds []*Trigger `json:"attachAlgoOrds,omitempty"` // 触发后下单附带的止盈止损
+}
+
+func (r *TriggerOrderRequest) Validate() error {
+	return firstError(
+		r.AlgoOrderRequest.Validate(),
+		checkRequired("triggerPx", r.TriggerPx),
+		checkRequired("orderPx", r.OrderPx),
+	)
+}
+
+// MoveOrderStopRequest 移动止盈止损参数，callbackRatio和callbackSpread必须传一个
+type MoveOrderStopRequest struct {
+	AlgoOrderRequest
+	CallbackRatio  string `json:"callbackRatio,omitempty"`  // 回调幅度的比例，如0.05代表5%
+	CallbackSpread string `json:"callbackSpread,omitempty"` // 回调幅度的价距
+	ActivePx       string `json:"activePx,omitempty"`       // 激活价格，为空时立即激活
+}
+
+func (r *MoveOrderStopRequest) Validate() error {
+	if err := r.AlgoOrderRequest.Validate(); err != nil {
+		return err
+	}
+
+	if (r.CallbackRatio == "") == (r.CallbackSpread == "") {
+		return fmt.Errorf("one of callbackRatio and callbackSpread is required")
+	}
+
+	return nil
+}
+
+// IcebergOrderRequest 冰山委托参数，pxVar和pxSpread必须传一个
+type IcebergOrderRequest struct {
+	AlgoOrderRequest
+	PxVar    string `json:"pxVar,omitempty"`    // 距离盘口的比例
+	PxSpread string `json:"pxSpread,omitempty"` // 距离盘口的价距
+	SzLimit  string `json:"szLimit"`            // 单笔数量
+	PxLimit  string `json:"pxLimit"`            // 挂单限制价
+}
+
+func (r *IcebergOrderRequest) Validate() error {
+	return firstError(
+		r.AlgoOrderRequest.Validate(),
+		checkRequired("pxVar or pxSpread", r.PxVar+r.PxSpread),
+		checkRequired("szLimit", r.SzLimit),
+		checkRequired("pxLimit", r.PxLimit),
+	)
+}
+
+// TwapOrderRequest 时间加权委托参数，pxVar和pxSpread必须传一个
+type TwapOrderRequest struct {
+	IcebergOrderRequest
+	TimeInterval string `json:"timeInterval"` // 下单间隔，单位秒
+}
+
+func (r *TwapOrderRequest) Validate() error {
+	return firstError(
+		r.IcebergOrderRequest.Validate(),
+		checkRequired("timeInterval", r.TimeInterval),
+	)
+}
+
+// CancelAlgoRequest 撤销策略委托参数，algoId和algoClOrdId必须传一个
+type CancelAlgoRequest struct {
+	InstId      string `json:"instId"`
+	AlgoId      string `json:"algoId,omitempty"`
+	AlgoClOrdId string `json:"algoClOrdId,omitempty"`
+}
+
+func (r *CancelAlgoRequest) Validate() error {
+	return firstError(
+		checkRequired("instId", r.InstId),
+		checkRequired("algoId or algoClOrdId", r.AlgoId+r.AlgoClOrdId),
+	)
+}
+
+// AmendAlgoRequest 修改策略委托参数，支持止盈止损和计划委托，algoId和algoClOrdId必须传一个
+type AmendAlgoRequest struct {
+	InstId             string `json:"instId"`
+	AlgoId             string `json:"algoId,omitempty"`
+	AlgoClOrdId        string `json:"algoClOrdId,omitempty"`
+	CxlOnFail          bool   `json:"cxlOnFail,omitempty"` // 修改失败时是否自动撤单
+	ReqId              string `json:"reqId,omitempty"`
+	NewSz              string `json:"newSz,omitempty"`
+	NewTpTriggerPx     string `json:"newTpTriggerPx,omitempty"` // 为0时删除止盈
+	NewTpOrdPx         string `json:"newTpOrdPx,omitempty"`
+	NewTpTriggerPxType string `json:"newTpTriggerPxType,omitempty"`
+	NewSlTriggerPx     string `json:"newSlTriggerPx,omitempty"` // 为0时删除止损
+	NewSlOrdPx         string `json:"newSlOrdPx,omitempty"`
+	NewSlTriggerPxType string `json:"newSlTriggerPxType,omitempty"`
+	NewTriggerPx       string `json:"newTriggerPx,omitempty"` // 计划委托的触发价
+	NewOrdPx           string `json:"newOrdPx,omitempty"`     // 计划委托的委托价
+	NewTriggerPxType   string `json:"newTriggerPxType,omitempty"`
+}
+
+func (r *AmendAlgoRequest) Validate() error {
+	return firstError(
+		checkRequired("instId", r.InstId),
+		checkRequired("algoId or algoClOrdId", r.AlgoId+r.AlgoClOrdId),
+		checkRequired("new value", r.NewSz+r.NewTpTriggerPx+r.NewTpOrdPx+r.NewSlTriggerPx+r.NewSlOrdPx+r.NewTriggerPx+r.NewOrdPx),
+	)
+}
+
+// AlgoOrdersRequest 策略委托列表查询参数，ordType必填，多个类型用逗号分隔，如conditional,oco
+// 查询历史时state和algoId必须传一个
+type AlgoOrdersRequest struct {
+	OrdType  string `url:"ordType"`
+	AlgoId   string `url:"algoId"`
+	State    string `url:"state"` // 仅历史查询，effective、canceled或order_failed
+	InstType string `url:"instType"`
+	InstId   string `url:"instId"`
+	After    string `url:"after"`  // 返回此algoId之前（更旧）的数据
+	Before   string `url:"before"` // 返回此algoId之后（更新）的数据
+	Limit    int    `url:"limit"`  // 最大100，默认100
+}
+
+func (r *AlgoOrdersRequest) Validate() error {
+	return firstError(
+		checkRequired("ordType", r.OrdType),
+		checkOneOf("instType", r.InstType, SPOT, MARGIN, SWAP, FUTURES),
+		checkOneOf("state", r.State, AlgoEffective, AlgoCanceled, AlgoOrderFailed),
+		checkLimit(r.Limit, pageLimit),
+	)
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkRequired(name, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required", name)
+	}
+
+	return nil
+}
+
+// checkOneOf 检查value是否为允许的值，value为空时不检查
+func checkOneOf(name, value string, allowed ...string) error {
+	if value == "" {
+		return nil
+	}
+
+	for _, v := range allowed {
+		if value == v {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid %s: %s, must be one of %s", name, value, strings.Join(allowed, ", "))
+}
+
+// checkLimit 检查分页条数，0表示使用接口的默认值
+func checkLimit(limit, max int) error {
+	if limit < 0 || limit > max {
+		return fmt.Errorf("invalid limit: %d, must be between 1 and %d, or 0 for the default", limit, max)
+	}
+
+	return nil
+}
+
+func checkRange(begin, end time.Time) error {
+	if !begin.IsZero() && !end.IsZero() && begin.After(end) {
+		return fmt.Errorf("begin %v is after end %v", begin, end)
+	}
+
+	return nil
+}
+
+// encodeQuery 按url标签把参数编码为查询字符串，零值字段会被忽略
+func encodeQuery(v interface{}) string {
+	values := url.Values{}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name := rt.Field(i).Tag.Get("url")
+		if name == "" || rv.Field(i).IsZero() {
+			continue
+		}
+
+		switch field := rv.Field(i).Interface().(type) {
+		case time.Time:
+			values.Set(name, strconv.FormatInt(field.UnixMilli(), 10))
+		default:
+			values.Set(name, fmt.Sprint(field))
+		}
+	}
+
+	return values.Encode()
+}
+
+// atoi 解析旧接口中字符串形式的数字参数，空字符串返回0
+func atoi(name, s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", name, s)
+	}
+
+	return i, nil
+}
+
+// parseMilli 解析旧接口中毫秒时间戳形式的参数，空字符串返回零值
+func parseMilli(name, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %s", name, s)
+	}
+
+	return time.UnixMilli(ms), nil
+}
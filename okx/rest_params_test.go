@@ -0,0 +1,53 @@
+package okx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEncodeQuery(t *testing.T) {
+	q := encodeQuery(&BillsRequest{
+		InstType: SWAP,
+		MgnMode:  Cross,
+		Begin:    time.UnixMilli(1700000000000),
+		Limit:    50,
+	})
+
+	if want := "begin=1700000000000&instType=SWAP&limit=50&mgnMode=cross"; q != want {
+		t.Errorf("query = %s, want %s", q, want)
+	}
+}
+
+func TestRequestValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		req  interface{ Validate() error }
+		ok   bool
+	}{
+		{"market order", &PlaceOrderRequest{InstId: "BTC-USDT", TdMode: Cash, Side: Buy, OrdType: Market, Sz: "1"}, true},
+		{"limit order without px", &PlaceOrderRequest{InstId: "BTC-USDT", TdMode: Cash, Side: Buy, OrdType: Limit, Sz: "1"}, false},
+		{"swapped mgnMode and ctType", &BillsRequest{MgnMode: Linear, CtType: Cross}, false},
+		{"begin after end", &BillsRequest{Begin: time.Now(), End: time.Now().Add(-time.Hour)}, false},
+		{"limit too large", &OrdersPendingRequest{Limit: 1000}, false},
+		{"default limit", &OrdersPendingRequest{Limit: 0}, true},
+		{"invalid state", &OrdersPendingRequest{State: Filled}, false},
+		{"positions history", &PositionsHistoryRequest{InstType: SWAP, MgnMode: Isolated}, true},
+		{"amend px", &AmendOrderRequest{InstId: "BTC-USDT", ClOrdId: "a1", NewPx: "40000"}, true},
+		{"amend without ids", &AmendOrderRequest{InstId: "BTC-USDT", NewPx: "40000"}, false},
+		{"amend nothing", &AmendOrderRequest{InstId: "BTC-USDT", OrdId: "1"}, false},
+		{"amend tp", &AmendOrderRequest{InstId: "BTC-USDT", OrdId: "1", AttachAlgoOrds: []*AmendTrigger{{AttachAlgoId: "2", NewTpTriggerPx: "45000"}}}, true},
+		{"oco without sl", &TpSlOrderRequest{AlgoOrderRequest: AlgoOrderRequest{InstId: "BTC-USDT", TdMode: Cash, Side: Sell, OrdType: Oco, Sz: "1"}, TpTriggerPx: "45000", TpOrdPx: "-1"}, false},
+		{"condition with sl", &TpSlOrderRequest{AlgoOrderRequest: AlgoOrderRequest{InstId: "BTC-USDT", TdMode: Cash, Side: Sell, OrdType: Conditional, CloseFraction: "1"}, SlTriggerPx: "39000", SlOrdPx: "-1"}, true},
+		{"move order stop with both callbacks", &MoveOrderStopRequest{AlgoOrderRequest: AlgoOrderRequest{InstId: "BTC-USDT", TdMode: Cash, Side: Sell, Sz: "1"}, CallbackRatio: "0.05", CallbackSpread: "100"}, false},
+		{"twap without interval", &TwapOrderRequest{IcebergOrderRequest: IcebergOrderRequest{AlgoOrderRequest: AlgoOrderRequest{InstId: "BTC-USDT", TdMode: Cash, Side: Buy, Sz: "10"}, PxVar: "0.01", SzLimit: "1", PxLimit: "40000"}}, false},
+		{"amend algo", &AmendAlgoRequest{InstId: "BTC-USDT", AlgoId: "1", NewTriggerPx: "41000"}, true},
+		{"algo orders without ordType", &AlgoOrdersRequest{InstType: SPOT}, false},
+		{"amend tp without id", &AmendOrderRequest{InstId: "BTC-USDT", OrdId: "1", AttachAlgoOrds: []*AmendTrigger{{NewTpTriggerPx: "45000"}}}, false},
+	}
+
+	for _, c := range cases {
+		if err := c.req.Validate(); (err == nil) != c.ok {
+			t.Errorf("%s: err = %v", c.name, err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package okx
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RetryPolicy rest请求的重试策略
+// GET请求默认自动重试；下单请求只有在RetryOrders为true且所有订单都带有clOrdId时才会重试，
+// 重试的下单请求若已被交易所受理，会以clOrdId重复的错误返回
+type RetryPolicy struct {
+	MaxAttempts    int           // 最大尝试次数，包含第一次请求，小于等于1时不重试
+	BaseDelay      time.Duration // 第一次重试前的等待时间，之后按指数增长
+	MaxDelay       time.Duration // 单次等待时间上限
+	RateLimitDelay time.Duration // 触发限速时的最小等待时间
+	RetryOrders    bool          // 是否重试带clOrdId的下单请求
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	BaseDelay:      200 * time.Millisecond,
+	MaxDelay:       3 * time.Second,
+	RateLimitDelay: time.Second,
+}
+
+// NoRetryPolicy 不做任何重试
+var NoRetryPolicy = RetryPolicy{MaxAttempts: 1}
+
+// SetRetryPolicy 设置重试策略
+func (c *RestConfig) SetRetryPolicy(policy RetryPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retry = &policy
+}
+
+func (c *RestConfig) retryPolicy() RetryPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.retry == nil {
+		return DefaultRetryPolicy
+	}
+
+	return *c.retry
+}
+
+// backoff 返回第attempt次重试前的等待时间，使用指数退避并加入随机抖动
+func (p RetryPolicy) backoff(attempt int, err error) time.Duration {
+	d := p.BaseDelay << uint(attempt-1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+
+	if d > 0 {
+		d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	}
+
+	if IsRateLimited(err) && d < p.RateLimitDelay {
+		d = p.RateLimitDelay
+	}
+
+	return d
+}
+
+// retryable 判断本次请求失败后是否可以重试
+func (p RetryPolicy) retryable(err error, method, path string, msg interface{}) bool {
+	if !p.idempotent(method, path, msg) {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if e, ok := AsAPIError(err); ok {
+		// 批量接口的单项失败不能整体重试
+		return len(e.Items) == 0 && IsRetryable(err)
+	}
+
+	if IsRateLimited(err) {
+		return true
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// idempotent 判断请求是否可以安全地重复发送
+func (p RetryPolicy) idempotent(method, path string, msg interface{}) bool {
+	if method == http.MethodGet {
+		return true
+	}
+
+	if !p.RetryOrders {
+		return false
+	}
+
+	switch path {
+	case OrderUrl, BatchOrdersUrl:
+		return hasClOrdId(msg)
+	}
+
+	return false
+}
+
+func hasClOrdId(msg interface{}) bool {
+	switch v := msg.(type) {
+	case Params:
+		clOrdId, _ := v["clOrdId"].(string)
+		return clOrdId != ""
+	case *PlaceOrderRequest:
+		return v.ClOrdId != ""
+	case []*Order:
+		for _, order := range v {
+			if order.ClOrdId == "" {
+				return false
+			}
+		}
+		return len(v) > 0
+	}
+
+	return false
+}
+
+// sleepContext 等待d时间，ctx结束时提前返回错误
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package okx
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryGet(t *testing.T) {
+	var calls int
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return stubResponse(http.StatusTooManyRequests, `{"code":"50011","msg":"Too Many Requests","data":[]}`), nil
+		}
+		return stubResponse(http.StatusOK, `{"code":"0","msg":"","data":[{"instId":"BTC-USDT","last":"1"}]}`), nil
+	})
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, RateLimitDelay: 5 * time.Millisecond})
+
+	ticker, err := c.Ticker("BTC-USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ticker.Last != "1" || calls != 2 {
+		t.Errorf("last = %s, calls = %d", ticker.Last, calls)
+	}
+}
+
+func TestRetryOrders(t *testing.T) {
+	var calls int
+	c := newStubConfig(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return stubResponse(http.StatusServiceUnavailable, `{"code":"50001","msg":"Service temporarily unavailable","data":[]}`), nil
+	})
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})
+
+	// 未开启RetryOrders时下单请求不重试
+	if _, err := c.MakeOrder("BTC-USDT", Cash, "", "a1", Buy, Market, "", "1", false, "", "", false, nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+
+	calls = 0
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, RetryOrders: true})
+	if _, err := c.MakeOrder("BTC-USDT", Cash, "", "a1", Buy, Market, "", "1", false, "", "", false, nil); !IsRetryable(err) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+
+	// 没有clOrdId的下单请求不是幂等的
+	calls = 0
+	if _, err := c.MakeOrder("BTC-USDT", Cash, "", "", Buy, Market, "", "1", false, "", "", false, nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
@@ -3,14 +3,12 @@ package okx
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"github.com/hansdq/go-okx/common/utils"
 	"github.com/hansdq/recws"
-	"github.com/tidwall/gjson"
+	"github.com/pkg/errors"
 	"github.com/zeromicro/go-zero/core/logx"
-	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,44 +21,104 @@ type WebSocket struct {
 	cancel context.CancelFunc
 	conn   recws.RecConn
 
-	subscriptions map[string]interface{}
+	subscriptions map[SubscribeArg]*Subscription
+	subIds        map[string][]SubscribeArg // 等待确认的订阅请求
+	handlers      map[handlerKey][]Handler
+
+	queues       map[SubscribeArg]*msgQueue   // 已创建的消息队列
+	queueCfg     map[SubscribeArg]QueueConfig // 单独设置的队列配置
+	defaultQueue *QueueConfig                 // 为nil时同步处理推送
+	workers      sync.WaitGroup               // 处理队列消息的goroutine
+
+	auth      *RestConfig    // 私有频道的登录凭证
+	loggedIn  atomic.Bool    // 当前连接是否已登录
+	loggingIn atomic.Bool    // 是否正在等待登录结果
+	loginCh   chan SocketMsg // 登录结果
+
+	connGen      atomic.Int64 // 每次建立连接加1
+	loginFails   atomic.Int64 // 连续登录失败的次数
+	reconnecting atomic.Bool  // 已关闭连接，等待run触发重连
+
+	nextId  atomic.Int64                // 交易类操作的消息ID
+	pending map[string]chan *opResponse // 等待响应的交易类操作
+
+	listeners []func(event ConnEvent, err error)
+
+	proxyURL     *url.URL  // 为nil时使用环境变量中的代理
+	connThrottle *throttle // 建连限速
+	opThrottle   *throttle // subscribe、unsubscribe和login的限速
+
+	hbInterval time.Duration // 心跳间隔，为0时关闭心跳
+	hbTimeout  time.Duration // 等待pong的时间
+	lastRecv   atomic.Int64  // 最后一次收到消息的时间，纳秒
+	pingSent   atomic.Int64  // 等待pong的ping发送时间，纳秒，0表示没有
+	latency    atomic.Int64  // 最近一次ping/pong的往返时间
+	done       chan struct{} // run退出时关闭，未Start时为nil
+
+	closeOnce sync.Once
+	closed    chan struct{} // Close完成后关闭
 }
 
 type Trade struct {
+	InstId  string `json:"instId"`
 	SprdId  string `json:"sprdId"`
 	Side    string `json:"side"`
 	Sz      string `json:"sz"`
 	Px      string `json:"px"`
 	TradeId string `json:"tradeId"`
+	Count   string `json:"count"` // 聚合的成交笔数
 	Ts      string `json:"ts"`
 }
 
 type SocketMsg struct {
-	Event string `json:"event"`
-	Code  string `json:"code,omitempty"`
-	Msg   string `json:"msg,omitempty"`
+	Id    string        `json:"id,omitempty"` // 交易类操作的消息ID
+	Op    string        `json:"op,omitempty"`
+	Event string        `json:"event"`
+	Arg   *SubscribeArg `json:"arg,omitempty"`
+	Code  string        `json:"code,omitempty"`
+	Msg   string        `json:"msg,omitempty"`
 }
 
 type SubscribeMsg struct {
+	Id   string         `json:"id,omitempty"`
 	Op   string         `json:"op"`
 	Args []SubscribeArg `json:"args"`
 }
 
 type SubscribeArg struct {
-	Channel string `json:"channel"`
-	InstId  string `json:"instId,omitempty"`
-	SprdId  string `json:"sprdId,omitempty"`
+	Channel    string `json:"channel"`
+	InstType   string `json:"instType,omitempty"`
+	InstFamily string `json:"instFamily,omitempty"`
+	InstId     string `json:"instId,omitempty"`
+	SprdId     string `json:"sprdId,omitempty"`
+	Ccy        string `json:"ccy,omitempty"`
+}
+
+// PushMsg 推送的数据消息
+type PushMsg struct {
+	Arg    SubscribeArg    `json:"arg"`
+	Action string          `json:"action,omitempty"` // 深度频道的snapshot或update
+	Data   json.RawMessage `json:"data"`
 }
 
 func InitWebSocket(url string) *WebSocket {
 	ws := &WebSocket{
 		wsURL:         url,
-		subscriptions: make(map[string]interface{}),
+		subscriptions: make(map[SubscribeArg]*Subscription),
+		subIds:        make(map[string][]SubscribeArg),
+		handlers:      make(map[handlerKey][]Handler),
+		queues:        make(map[SubscribeArg]*msgQueue),
+		queueCfg:      make(map[SubscribeArg]QueueConfig),
+		loginCh:       make(chan SocketMsg, 1),
+		pending:       make(map[string]chan *opResponse),
+		closed:        make(chan struct{}),
 	}
 	ws.ctx, ws.cancel = context.WithCancel(context.Background())
-	ws.conn = recws.RecConn{
-		KeepAliveTimeout: 10 * time.Second,
-	}
+	// 使用OKX要求的文本ping/pong心跳，见heartbeat
+	ws.conn = recws.RecConn{}
+	ws.hbInterval, ws.hbTimeout = DefaultHeartbeatInterval, DefaultHeartbeatTimeout
+	ws.connThrottle, ws.opThrottle = sharedConnThrottle, newThrottle(opLimit, time.Hour)
+	ws.conn.Proxy = ws.proxy
 
 	ws.conn.SubscribeHandler = ws.subscribeHandler
 	return ws
@@ -76,59 +134,155 @@ func (w *WebSocket) SetProxy(proxyURL string) (err error) {
 		return
 	}
 	logx.Infof("[ws][%s] proxy url:%s", proxyURL, purl)
-	w.conn.Proxy = http.ProxyURL(purl)
+	w.Lock()
+	w.proxyURL = purl
+	w.Unlock()
 	return
 }
 
 func (w *WebSocket) Start() {
 	logx.Infof("wsURL: %v", w.wsURL)
-	w.conn.Dial(w.wsURL, nil)
+	w.Lock()
+	w.done = make(chan struct{})
+	w.Unlock()
+
+	// 每次拨号前限制建连频率
+	w.conn.Proxy = w.beforeDial(w.conn.Proxy)
+
+	// 登录需要在连接建立后读取响应，因此先开始读取
 	go w.run()
+	go w.heartbeat()
+	w.conn.Dial(w.wsURL, nil)
 }
 
+// Subscribe 订阅sub中的所有频道，channel不再使用
+//
+// Deprecated: 使用SubscribeArgs
 func (w *WebSocket) Subscribe(channel string, sub *SubscribeMsg) error {
-	w.Lock()
-	defer w.Unlock()
-	w.subscriptions[channel] = sub
-	return w.sendWSMessage(sub)
+	return w.SubscribeArgs(sub.Args...)
 }
 
+// subscribeHandler 连接建立后登录并恢复订阅
 func (w *WebSocket) subscribeHandler() error {
-	for _, v := range w.subscriptions {
-		err := w.sendWSMessage(v)
-		if err != nil {
-			logx.Errorf("%v", err)
-			return err
+	if w.ctx.Err() != nil {
+		// 已关闭，不再使用重连的连接
+		w.conn.Close()
+		return nil
+	}
+
+	w.connGen.Add(1)
+	w.emit(EventConnected, nil)
+	w.resetHeartbeat()
+	w.loggedIn.Store(false)
+	if w.auth != nil {
+		if err := w.login(); err != nil {
+			// 返回错误会导致recws退出进程
+			logx.Errorf("[ws] login failed: %v", err)
+			w.emit(EventLoginFailed, err)
+			w.retryLogin()
+			return nil
 		}
+		w.loginFails.Store(0)
+		w.loggedIn.Store(true)
 	}
+
+	if err := w.resubscribeAll(); err != nil {
+		logx.Errorf("[ws] resubscribe failed: %v", err)
+		return nil
+	}
+
+	w.emit(EventResubscribed, nil)
 	return nil
 }
 
+// retryLogin 登录失败后按退避时间断开重连，重连后重新登录并恢复订阅
+func (w *WebSocket) retryLogin() {
+	gen := w.connGen.Load()
+	delay := loginRetryPolicy.backoff(int(w.loginFails.Add(1)), nil)
+	go func() {
+		if sleepContext(w.ctx, delay) != nil || w.connGen.Load() != gen {
+			// 已关闭或者已经重连
+			return
+		}
+
+		logx.Infof("[ws] retry login after %v", delay)
+		w.reconnect()
+	}()
+}
+
+// reconnect 关闭当前连接，由run触发recws重连
+// 不直接调用CloseAndReconnect，否则正在进行的读取失败后recws会再重连一次
+func (w *WebSocket) reconnect() {
+	w.reconnecting.Store(true)
+	w.conn.Close()
+}
+
 func (w *WebSocket) sendWSMessage(msg interface{}) error {
 	return w.conn.WriteJSON(msg)
 }
 
 func (w *WebSocket) run() {
-	ctx := context.Background()
-	for {
-		select {
-		case <-ctx.Done():
-			go w.conn.Close()
-			logx.Infof("Websocket closed %s", w.conn.GetURL())
-			return
-		default:
-			_, msg, err := w.conn.ReadMessage()
-			if err != nil {
-				logx.Errorf("Read error: %v", err)
-				time.Sleep(time.Millisecond * 200)
+	defer close(w.done)
+
+	for w.ctx.Err() == nil {
+		_, msg, err := w.conn.ReadMessage()
+		if w.ctx.Err() != nil {
+			break
+		}
+
+		if errors.Is(err, recws.ErrNotConnected) {
+			if w.reconnecting.Swap(false) {
+				// reconnect关闭连接时没有正在进行的读取
+				w.conn.CloseAndReconnect()
+				w.disconnected(err)
 				continue
 			}
+
+			// 等待重连
+			_ = sleepContext(w.ctx, 200*time.Millisecond)
+			continue
+		}
+
+		if err != nil {
+			// recws在读取失败后会自动重连
+			w.reconnecting.Store(false)
+			logx.Errorf("[ws] read error: %v", err)
+			w.disconnected(err)
+			continue
+		}
+
+		if msg == nil && !w.conn.IsConnected() {
+			// 收到正常关闭帧时recws只关闭连接，不会重连
+			w.reconnecting.Store(false)
+			logx.Infof("[ws] closed by server, reconnecting")
+			w.conn.CloseAndReconnect()
+			w.disconnected(errServerClosed)
+			continue
+		}
+
+		w.lastRecv.Store(time.Now().UnixNano())
+		if msg != nil {
 			w.handle(msg)
 		}
 	}
+
+	logx.Infof("[ws] closed %s", w.wsURL)
+}
+
+// disconnected 连接断开后清理登录状态和等待中的操作
+func (w *WebSocket) disconnected(err error) {
+	w.loggedIn.Store(false)
+	w.failPending(err)
+	w.emit(EventDisconnected, err)
+	w.emit(EventReconnecting, nil)
 }
 
 func (w *WebSocket) handle(b []byte) {
+	if string(b) == "pong" {
+		w.pong()
+		return
+	}
+
 	var msg SocketMsg
 	err := json.Unmarshal(b, &msg)
 	if err != nil {
@@ -136,49 +290,32 @@ func (w *WebSocket) handle(b []byte) {
 		return
 	}
 
+	if msg.Event == "" && msg.Id != "" {
+		w.reply(msg.Id, b)
+		return
+	}
+
 	switch msg.Event {
+	case "login":
+		w.loginResult(msg)
 	case "error":
+		if w.loginResult(msg) || w.subscribeFailed(msg) {
+			return
+		}
 		logx.Error(msg.Msg)
 	case "subscribe":
-		logx.Infof("subscribe success: %v", string(b))
+		w.subscribed(msg)
 	case "unsubscribe":
 		logx.Infof("unsubscribe success: %v", string(b))
 	default:
-		ret := gjson.ParseBytes(b)
-		channel := ret.Get("arg").Get("channel").String()
-		action := ret.Get("action").String()
-		w.subscribe(channel, action, ret.Get("data").String())
-	}
-}
-
-func (w *WebSocket) subscribe(channel string, action string, data string) {
-	switch channel {
-	case "trades":
-		var trades []*Trade
-		err := json.Unmarshal([]byte(data), &trades)
-		if err != nil {
+		var push PushMsg
+		if err := json.Unmarshal(b, &push); err != nil {
 			logx.Error(err)
 			return
 		}
 
-		trade := trades[0]
-		sz := utils.MustParseFloat64(trade.Sz)
-		ts := utils.MustParseInt64(trade.Ts)
-		if sz >= 1000 {
-			fmt.Println(channel, time.UnixMilli(ts).Format("2006-01-02 15:04:05"), trade.Side, trade.Px, sz)
-		}
-	case "books":
-		var books []*Book
-		err := json.Unmarshal([]byte(data), &books)
-		if err != nil {
-			logx.Error(err)
-			return
-		}
-
-		if action == "snapshot" {
-
-		} else if action == "update" {
-
+		if push.Arg.Channel != "" && push.Data != nil {
+			w.deliver(&push)
 		}
 	}
 }
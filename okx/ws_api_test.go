@@ -0,0 +1,161 @@
+package okx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDispatch(t *testing.T) {
+	ws := InitWebSocket(SocketPubUrl)
+
+	var trades []*Trade
+	ws.OnTrades("BTC-USDT", func(push *Push[*Trade]) {
+		trades = append(trades, push.Data...)
+	})
+
+	all := make(chan *Push[*Trade], 10)
+	ws.OnTrades("", ToChan(all))
+
+	var actions []string
+	ws.OnBooks("books", "BTC-USDT", func(push *Push[*Book]) {
+		actions = append(actions, push.Action)
+		if push.Data[0].SeqId != 2 {
+			t.Errorf("seqId = %d", push.Data[0].SeqId)
+		}
+	})
+
+	var candles []*Candles
+	ws.OnCandles("candle1m", "BTC-USDT", func(push *Push[*Candles]) {
+		candles = append(candles, push.Data...)
+	})
+
+	ws.handle([]byte(`{"arg":{"channel":"trades","instId":"BTC-USDT"},"data":[{"instId":"BTC-USDT","tradeId":"1","px":"42219.9","sz":"0.12","side":"buy","ts":"1630048897897","count":"3"}]}`))
+	ws.handle([]byte(`{"arg":{"channel":"trades","instId":"ETH-USDT"},"data":[{"instId":"ETH-USDT","tradeId":"2","px":"2000","sz":"1","side":"sell","ts":"1630048897898"}]}`))
+	ws.handle([]byte(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"snapshot","data":[{"asks":[["8476.98","415","0","13"]],"bids":[["8476.97","256","0","12"]],"ts":"1597026383085","checksum":-855196043,"prevSeqId":-1,"seqId":2}]}`))
+	ws.handle([]byte(`{"arg":{"channel":"candle1m","instId":"BTC-USDT"},"data":[["1597026383085","8533.02","8553.74","8527.17","8548.26","45247","529.5858061","529.5858061","0"]]}`))
+	ws.handle([]byte(`{"event":"subscribe","arg":{"channel":"trades","instId":"BTC-USDT"}}`))
+
+	if len(trades) != 1 || trades[0].Px != "42219.9" || trades[0].Count != "3" {
+		t.Errorf("trades = %+v", trades)
+	}
+
+	if len(all) != 2 {
+		t.Errorf("wildcard handler received %d pushes, want 2", len(all))
+	}
+
+	if len(actions) != 1 || actions[0] != "snapshot" {
+		t.Errorf("actions = %v", actions)
+	}
+
+	if len(candles) != 1 || candles[0].ClosePx.String() != "8548.26" {
+		t.Errorf("candles = %+v", candles)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	c := &RestConfig{ApiKey: "key", SecretKey: "secret", Password: "pass"}
+	msg := loginMsg(c, "1538054050")
+
+	h := hmac.New(sha256.New, []byte("secret"))
+	h.Write([]byte("1538054050GET/users/self/verify"))
+	if want := base64.StdEncoding.EncodeToString(h.Sum(nil)); msg.Args[0].Sign != want {
+		t.Errorf("sign = %s, want %s", msg.Args[0].Sign, want)
+	}
+
+	ws := InitWebSocket(SocketPriGlobalUrl)
+	ws.SetCredentials(c)
+
+	// 登录前的订阅只会被记录，登录后统一发送
+	if err := ws.SubscribeOrders(SWAP, ""); err != nil {
+		t.Errorf("subscribe before login: %v", err)
+	}
+
+	ws.loggingIn.Store(true)
+	ws.handle([]byte(`{"event":"error","code":"60009","msg":"Login failed."}`))
+	select {
+	case m := <-ws.loginCh:
+		if m.Code != "60009" {
+			t.Errorf("login result = %+v", m)
+		}
+	default:
+		t.Error("login result not delivered")
+	}
+}
+
+// newTestServer 启动模拟的websocket服务，reply返回对每条请求的响应，响应为空字符串时断开连接
+func newTestServer(t *testing.T, reply func(req map[string]interface{}) []string) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(rw, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			_, b, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			// 文本心跳转换为op为ping的请求
+			req := map[string]interface{}{"op": "ping"}
+			if string(b) != "ping" {
+				if err := json.Unmarshal(b, &req); err != nil {
+					return
+				}
+			}
+
+			for _, resp := range reply(req) {
+				if resp == "" {
+					return
+				}
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(resp)); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestWebSocket 返回连接到模拟服务的WebSocket，尚未Start
+func newTestWebSocket(t *testing.T, srv *httptest.Server) *WebSocket {
+	ws := InitWebSocket("ws" + strings.TrimPrefix(srv.URL, "http"))
+	ws.conn.HandshakeTimeout = 100 * time.Millisecond
+	ws.conn.RecIntvlMin = 10 * time.Millisecond
+	ws.conn.RecIntvlMax = 100 * time.Millisecond
+	ws.conn.NonVerbose = true
+	// 测试中频繁建连，不使用共享的建连限速
+	ws.connThrottle = newThrottle(1000, time.Second)
+	ws.SetCredentials(&RestConfig{ApiKey: "key", SecretKey: "secret", Password: "pass"})
+	t.Cleanup(func() {
+		ws.Close()
+		ws.Wait()
+	})
+	return ws
+}
+
+// startTestWebSocket 连接到模拟服务并等待登录完成
+func startTestWebSocket(t *testing.T, srv *httptest.Server) *WebSocket {
+	ws := newTestWebSocket(t, srv)
+	ws.Start()
+
+	for i := 0; !ws.loggedIn.Load(); i++ {
+		if i > 100 {
+			t.Fatal("login timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return ws
+}
@@ -0,0 +1,286 @@
+package okx
+
+import (
+	"fmt"
+	"github.com/zeromicro/go-zero/core/logx"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// checksumDepth 计算校验和使用的档位数
+const checksumDepth = 25
+
+// PriceLevel 深度的一档
+type PriceLevel struct {
+	Px     Decimal
+	Sz     Decimal
+	Orders int64 // 此价格的订单数量
+
+	px, sz string // 推送的原始字符串，用于计算校验和
+}
+
+// OrderBook 根据深度频道的快照和增量推送在本地维护的订单簿
+// 校验和不一致或序号不连续时会重新订阅，在收到新的快照之前Ready返回false
+type OrderBook struct {
+	mu sync.RWMutex
+
+	ws  *WebSocket
+	arg SubscribeArg
+
+	asks  []*PriceLevel // 价格从低到高
+	bids  []*PriceLevel // 价格从高到低
+	seqId int64
+	ts    int64
+	ready bool
+
+	resyncing atomic.Bool // 是否正在重新订阅，期间的错误不再重复订阅
+
+	listeners []func(b *OrderBook)
+}
+
+// NewOrderBook 订阅instId的深度频道并维护本地订单簿，channel可以是books、books5、books-l2-tbt、books50-l2-tbt等
+func NewOrderBook(ws *WebSocket, channel, instId string) (*OrderBook, error) {
+	b := &OrderBook{ws: ws, arg: SubscribeArg{Channel: channel, InstId: instId}}
+	ws.OnBooks(channel, instId, b.handle)
+	if err := ws.SubscribeArgs(b.arg); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// OnChange 注册订单簿变化后的回调，在读取消息的goroutine中调用
+func (b *OrderBook) OnChange(fn func(b *OrderBook)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, fn)
+}
+
+// Ready 是否已收到快照且数据有效
+func (b *OrderBook) Ready() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.ready
+}
+
+// Ts 最后一次推送的时间戳，毫秒
+func (b *OrderBook) Ts() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.ts
+}
+
+// BestBid 买一
+func (b *OrderBook) BestBid() (PriceLevel, bool) {
+	return b.best(&b.bids)
+}
+
+// BestAsk 卖一
+func (b *OrderBook) BestAsk() (PriceLevel, bool) {
+	return b.best(&b.asks)
+}
+
+// Bids 买方前depth档，depth小于等于0时返回全部
+func (b *OrderBook) Bids(depth int) []PriceLevel {
+	return b.depth(&b.bids, depth)
+}
+
+// Asks 卖方前depth档，depth小于等于0时返回全部
+func (b *OrderBook) Asks(depth int) []PriceLevel {
+	return b.depth(&b.asks, depth)
+}
+
+func (b *OrderBook) best(side *[]*PriceLevel) (PriceLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if !b.ready || len(*side) == 0 {
+		return PriceLevel{}, false
+	}
+
+	return *(*side)[0], true
+}
+
+func (b *OrderBook) depth(side *[]*PriceLevel, depth int) []PriceLevel {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if !b.ready {
+		return nil
+	}
+
+	levels := *side
+	if depth > 0 && depth < len(levels) {
+		levels = levels[:depth]
+	}
+
+	ret := make([]PriceLevel, len(levels))
+	for i, level := range levels {
+		ret[i] = *level
+	}
+
+	return ret
+}
+
+func (b *OrderBook) handle(push *Push[*Book]) {
+	for _, book := range push.Data {
+		if err := b.apply(push.Action, book); err != nil {
+			logx.Errorf("[ws] order book %s %s: %v, resubscribing", b.arg.Channel, b.arg.InstId, err)
+			b.reset()
+			if b.resyncing.CompareAndSwap(false, true) {
+				go b.resync()
+			}
+			return
+		}
+	}
+
+	b.mu.RLock()
+	listeners := b.listeners
+	b.mu.RUnlock()
+
+	for _, fn := range listeners {
+		fn(b)
+	}
+}
+
+// apply 应用快照或增量数据，没有action的频道每次推送的都是完整数据
+func (b *OrderBook) apply(action string, book *Book) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	asks, err := parseLevels(book.Asks)
+	if err != nil {
+		return err
+	}
+
+	bids, err := parseLevels(book.Bids)
+	if err != nil {
+		return err
+	}
+
+	if action == "update" {
+		if !b.ready {
+			// 等待快照
+			return nil
+		}
+
+		// 没有变化时seqId与prevSeqId相同
+		if book.PrevSeqId != b.seqId {
+			return fmt.Errorf("sequence gap: prevSeqId %d, want %d", book.PrevSeqId, b.seqId)
+		}
+
+		for _, level := range asks {
+			b.asks = updateLevel(b.asks, level, 1)
+		}
+		for _, level := range bids {
+			b.bids = updateLevel(b.bids, level, -1)
+		}
+	} else {
+		sortLevels(asks, 1)
+		sortLevels(bids, -1)
+		b.asks, b.bids = asks, bids
+	}
+
+	b.seqId = book.SeqId
+	b.ts, _ = strconv.ParseInt(book.Ts, 10, 64)
+	b.ready = true
+
+	if book.Checksum != 0 {
+		if sum := checksum(b.bids, b.asks); sum != int32(book.Checksum) {
+			b.ready = false
+			return fmt.Errorf("checksum mismatch: %d, want %d", sum, book.Checksum)
+		}
+	}
+
+	return nil
+}
+
+// resync 重新订阅以获取新的快照，订阅可能因限速等待很久，不能阻塞处理推送的goroutine
+func (b *OrderBook) resync() {
+	defer b.resyncing.Store(false)
+
+	if b.ws.ctx.Err() != nil {
+		return
+	}
+
+	if err := b.ws.resubscribe(b.arg); err != nil {
+		logx.Errorf("[ws] resubscribe %s %s failed: %v", b.arg.Channel, b.arg.InstId, err)
+	}
+}
+
+func (b *OrderBook) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.asks, b.bids = nil, nil
+	b.seqId = 0
+	b.ready = false
+}
+
+func parseLevels(items [][]string) ([]*PriceLevel, error) {
+	levels := make([]*PriceLevel, 0, len(items))
+	for _, item := range items {
+		order, err := parseBookOrder(item)
+		if err != nil {
+			return nil, err
+		}
+
+		levels = append(levels, &PriceLevel{
+			Px:     order.Px,
+			Sz:     order.Sz,
+			Orders: order.OrderQuantity,
+			px:     item[0],
+			sz:     item[1],
+		})
+	}
+
+	return levels, nil
+}
+
+// sortLevels 按价格排序，dir为1时从低到高，-1时从高到低
+func sortLevels(levels []*PriceLevel, dir int) {
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].Px.Cmp(levels[j].Px) == -dir
+	})
+}
+
+// updateLevel 更新一档，数量为0时删除
+func updateLevel(levels []*PriceLevel, level *PriceLevel, dir int) []*PriceLevel {
+	i := sort.Search(len(levels), func(i int) bool {
+		return levels[i].Px.Cmp(level.Px)*dir >= 0
+	})
+
+	found := i < len(levels) && levels[i].Px.Equal(level.Px)
+	switch {
+	case level.Sz.IsZero() && found:
+		return append(levels[:i], levels[i+1:]...)
+	case level.Sz.IsZero():
+		return levels
+	case found:
+		levels[i] = level
+		return levels
+	}
+
+	levels = append(levels, nil)
+	copy(levels[i+1:], levels[i:])
+	levels[i] = level
+	return levels
+}
+
+// checksum 按OKX的规则计算前25档的校验和：买卖交替拼接price:size，一方不足时只拼接另一方
+func checksum(bids, asks []*PriceLevel) int32 {
+	var parts []string
+	for i := 0; i < checksumDepth; i++ {
+		if i < len(bids) {
+			parts = append(parts, bids[i].px, bids[i].sz)
+		}
+		if i < len(asks) {
+			parts = append(parts, asks[i].px, asks[i].sz)
+		}
+	}
+
+	return int32(crc32.ChecksumIEEE([]byte(strings.Join(parts, ":"))))
+}
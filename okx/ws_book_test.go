@@ -0,0 +1,120 @@
+package okx
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func bookPush(action string, prevSeqId, seqId int64, asks, bids, sum string) []byte {
+	return []byte(fmt.Sprintf(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"%s","data":[{"asks":%s,"bids":%s,"ts":"1597026383085","checksum":%d,"prevSeqId":%d,"seqId":%d}]}`,
+		action, asks, bids, int32(crc32.ChecksumIEEE([]byte(sum))), prevSeqId, seqId))
+}
+
+func TestOrderBook(t *testing.T) {
+	ws := InitWebSocket(SocketPubUrl)
+	book, err := NewOrderBook(ws, "books", "BTC-USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := 0
+	book.OnChange(func(b *OrderBook) {
+		changes++
+	})
+
+	ws.handle(bookPush("snapshot", -1, 10,
+		`[["3366.8","9","10","3"],["3368","8","3","4"]]`,
+		`[["3366.1","7","0","3"],["3366","6","3","4"]]`,
+		"3366.1:7:3366.8:9:3366:6:3368:8"))
+
+	if bid, ok := book.BestBid(); !ok || bid.Px.String() != "3366.1" {
+		t.Fatalf("best bid = %+v, ready = %v", bid, book.Ready())
+	}
+
+	ws.handle(bookPush("update", 10, 11,
+		`[["3367","2","0","1"]]`,
+		`[["3366.1","0","0","0"]]`,
+		"3366:6:3366.8:9:3367:2:3368:8"))
+
+	if !book.Ready() {
+		t.Fatal("book not ready after update")
+	}
+
+	if ask, _ := book.BestAsk(); ask.Px.String() != "3366.8" {
+		t.Errorf("best ask = %s", ask.Px)
+	}
+
+	if asks := book.Asks(0); len(asks) != 3 || asks[1].Px.String() != "3367" {
+		t.Errorf("asks = %+v", asks)
+	}
+
+	if bids := book.Bids(5); len(bids) != 1 || bids[0].Px.String() != "3366" {
+		t.Errorf("bids = %+v", bids)
+	}
+
+	// 序号不连续
+	ws.handle(bookPush("update", 12, 13, `[]`, `[]`, "3366:6:3366.8:9:3367:2:3368:8"))
+	if book.Ready() {
+		t.Error("book ready after sequence gap")
+	}
+
+	if changes != 2 {
+		t.Errorf("changes = %d, want 2", changes)
+	}
+
+	// 重新收到快照后恢复
+	ws.handle(bookPush("snapshot", -1, 20, `[["3370","1","0","1"]]`, `[]`, "3370:1"))
+	if ask, ok := book.BestAsk(); !ok || ask.Sz.String() != "1" {
+		t.Errorf("best ask after resync = %+v", ask)
+	}
+
+	// 校验和错误
+	ws.handle(bookPush("update", 20, 21, `[["3371","1","0","1"]]`, `[]`, "3370:1"))
+	if book.Ready() {
+		t.Error("book ready after checksum mismatch")
+	}
+}
+
+func TestOrderBookResync(t *testing.T) {
+	ws := InitWebSocket(SocketPubUrl)
+	defer ws.Close()
+
+	// 用完订阅的限速，重新订阅需要等待
+	ws.opThrottle = newThrottle(1, time.Hour)
+	ws.opThrottle.reserve()
+
+	var throttled atomic.Int64
+	ws.OnEvent(func(event ConnEvent, err error) {
+		if event == EventThrottled {
+			throttled.Add(1)
+		}
+	})
+
+	book, err := NewOrderBook(ws, "books", "BTC-USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		ws.handle(bookPush("snapshot", -1, 10, `[["3370","1","0","1"]]`, `[]`, "3371:1"))
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("handle blocked for %v", d)
+	}
+
+	// 多次校验和错误只重新订阅一次
+	for i := 0; throttled.Load() == 0; i++ {
+		if i > 100 {
+			t.Fatal("resubscribe not started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := throttled.Load(); n != 1 || book.Ready() {
+		t.Errorf("throttled = %d, ready = %v", n, book.Ready())
+	}
+}
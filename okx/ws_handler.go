@@ -0,0 +1,123 @@
+package okx
+
+import (
+	"encoding/json"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Handler 处理推送的原始数据
+type Handler func(msg *PushMsg)
+
+// Push 解码后的推送数据
+type Push[T any] struct {
+	Arg    SubscribeArg
+	Action string // 深度频道的snapshot或update，其它频道为空
+	Data   []T
+}
+
+type handlerKey struct {
+	channel string
+	instId  string
+}
+
+// Handle 注册channel上instId的原始数据处理函数，instId为空时处理该频道的所有推送
+// 处理函数在读取消息的goroutine中依次调用，不能长时间阻塞，设置了队列的订阅在队列的goroutine中调用，见SetQueue
+func (w *WebSocket) Handle(channel, instId string, h Handler) {
+	w.Lock()
+	defer w.Unlock()
+	key := handlerKey{channel: channel, instId: instId}
+	w.handlers[key] = append(w.handlers[key], h)
+}
+
+// OnTrades 注册trades频道的处理函数
+func (w *WebSocket) OnTrades(instId string, fn func(push *Push[*Trade])) {
+	on(w, TradesChannel, instId, decodeJSON[*Trade], fn)
+}
+
+// OnTradesAll 注册trades-all频道的处理函数
+func (w *WebSocket) OnTradesAll(instId string, fn func(push *Push[*Trade])) {
+	on(w, TradesAllChannel, instId, decodeJSON[*Trade], fn)
+}
+
+// OnBooks 注册深度频道的处理函数，channel可以是books、books5、bbo-tbt、books-l2-tbt等
+func (w *WebSocket) OnBooks(channel, instId string, fn func(push *Push[*Book])) {
+	on(w, channel, instId, decodeJSON[*Book], fn)
+}
+
+// OnTickers 注册tickers频道的处理函数
+func (w *WebSocket) OnTickers(instId string, fn func(push *Push[*Ticker])) {
+	on(w, TickersChannel, instId, decodeJSON[*Ticker], fn)
+}
+
+// OnCandles 注册k线频道的处理函数，channel如candle1m、candle1H
+func (w *WebSocket) OnCandles(channel, instId string, fn func(push *Push[*Candles])) {
+	on(w, channel, instId, decodeCandles, fn)
+}
+
+// OnOrders 注册orders频道的处理函数
+func (w *WebSocket) OnOrders(instId string, fn func(push *Push[*Order])) {
+	on(w, OrdersChannel, instId, decodeJSON[*Order], fn)
+}
+
+// OnPositions 注册positions频道的处理函数
+func (w *WebSocket) OnPositions(instId string, fn func(push *Push[*Position])) {
+	on(w, PositionsChannel, instId, decodeJSON[*Position], fn)
+}
+
+// OnAccount 注册account频道的处理函数
+func (w *WebSocket) OnAccount(fn func(push *Push[*Account])) {
+	on(w, AccountChannel, "", decodeJSON[*Account], fn)
+}
+
+// ToChan 把推送数据转发到ch，可以作为On系列方法的处理函数
+// ch的消费速度会影响消息的读取，一般应使用带缓冲的channel
+//
+//	trades := make(chan *Push[*Trade], 100)
+//	ws.OnTrades("BTC-USDT", ToChan(trades))
+func ToChan[T any](ch chan<- *Push[T]) func(push *Push[T]) {
+	return func(push *Push[T]) {
+		ch <- push
+	}
+}
+
+// on 注册解码为T的处理函数
+func on[T any](w *WebSocket, channel, instId string, decode func(data []byte) ([]T, error), fn func(push *Push[T])) {
+	w.Handle(channel, instId, func(msg *PushMsg) {
+		data, err := decode(msg.Data)
+		if err != nil {
+			logx.Errorf("[ws] decode %s failed: %v", msg.Arg.Channel, err)
+			return
+		}
+
+		fn(&Push[T]{Arg: msg.Arg, Action: msg.Action, Data: data})
+	})
+}
+
+func decodeJSON[T any](data []byte) ([]T, error) {
+	var ret []T
+	err := json.Unmarshal(data, &ret)
+	return ret, err
+}
+
+func decodeCandles(data []byte) ([]*Candles, error) {
+	var items [][]string
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	return parseCandles(items)
+}
+
+// dispatch 把推送数据交给对应instId和整个频道的处理函数
+func (w *WebSocket) dispatch(msg *PushMsg) {
+	w.RLock()
+	handlers := w.handlers[handlerKey{channel: msg.Arg.Channel, instId: msg.Arg.InstId}]
+	if msg.Arg.InstId != "" {
+		handlers = append(handlers[:len(handlers):len(handlers)], w.handlers[handlerKey{channel: msg.Arg.Channel}]...)
+	}
+	w.RUnlock()
+
+	for _, h := range handlers {
+		h(msg)
+	}
+}
@@ -0,0 +1,78 @@
+package okx
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/zeromicro/go-zero/core/logx"
+	"time"
+)
+
+// SetHeartbeat 设置心跳，超过interval没有收到消息时发送ping，timeout内没有收到任何消息时断开并重连
+// interval为0时关闭心跳，默认DefaultHeartbeatInterval和DefaultHeartbeatTimeout
+func (w *WebSocket) SetHeartbeat(interval, timeout time.Duration) {
+	w.Lock()
+	defer w.Unlock()
+	w.hbInterval, w.hbTimeout = interval, timeout
+}
+
+// Latency 返回最近一次ping/pong的往返时间，还没有测量时返回0
+func (w *WebSocket) Latency() time.Duration {
+	return time.Duration(w.latency.Load())
+}
+
+func (w *WebSocket) resetHeartbeat() {
+	w.lastRecv.Store(time.Now().UnixNano())
+	w.pingSent.Store(0)
+}
+
+func (w *WebSocket) pong() {
+	if sent := w.pingSent.Swap(0); sent != 0 {
+		w.latency.Store(time.Now().UnixNano() - sent)
+	}
+}
+
+// heartbeat 按OKX的要求发送文本ping，检测不到pong时关闭连接并重连
+func (w *WebSocket) heartbeat() {
+	for {
+		w.RLock()
+		interval, timeout := w.hbInterval, w.hbTimeout
+		w.RUnlock()
+
+		// 检查周期取两个时间中较小值的一半
+		period := interval
+		if timeout > 0 && timeout < period {
+			period = timeout
+		}
+		if period <= 0 {
+			period = time.Second
+		} else {
+			period /= 2
+		}
+
+		if err := sleepContext(w.ctx, period); err != nil {
+			return
+		}
+
+		if interval <= 0 || !w.conn.IsConnected() {
+			continue
+		}
+
+		now := time.Now().UnixNano()
+		sent, last := w.pingSent.Load(), w.lastRecv.Load()
+		switch {
+		case sent != 0 && time.Duration(now-sent) > timeout:
+			w.pingSent.Store(0)
+			if last > sent {
+				// 没有收到pong但连接上仍有消息，开始新一轮心跳
+				continue
+			}
+
+			logx.Errorf("[ws] pong timeout after %v, reconnecting", time.Duration(now-sent))
+			w.reconnect()
+		case sent == 0 && time.Duration(now-last) >= interval:
+			w.pingSent.Store(now)
+			if err := w.conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+				logx.Errorf("[ws] send ping failed: %v", err)
+			}
+		}
+	}
+}
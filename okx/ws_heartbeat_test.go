@@ -0,0 +1,76 @@
+package okx
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHeartbeat(t *testing.T) {
+	var pong atomic.Bool
+	pong.Store(true)
+	srv := newTestServer(t, func(req map[string]interface{}) []string {
+		switch req["op"] {
+		case "login":
+			return []string{`{"event":"login","code":"0","msg":""}`}
+		case "ping":
+			if pong.Load() {
+				return []string{"pong"}
+			}
+		}
+		return nil
+	})
+
+	ws := newTestWebSocket(t, srv)
+	// 超时需要大于未连接时等待读取的间隔
+	ws.SetHeartbeat(50*time.Millisecond, 300*time.Millisecond)
+
+	var connected atomic.Int64
+	disconnected := make(chan error, 1)
+	ws.OnEvent(func(event ConnEvent, err error) {
+		if event == EventConnected {
+			connected.Add(1)
+		}
+		if event == EventDisconnected {
+			select {
+			case disconnected <- err:
+			default:
+			}
+		}
+	})
+	ws.Start()
+
+	for i := 0; ws.Latency() == 0; i++ {
+		if i > 100 {
+			t.Fatal("no pong received")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case err := <-disconnected:
+		t.Fatalf("disconnected while pong received: %v", err)
+	default:
+	}
+
+	// 没有pong时断开并重连
+	pong.Store(false)
+	select {
+	case <-disconnected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("pong timeout not detected")
+	}
+
+	// 只重连一次
+	pong.Store(true)
+	for i := 0; connected.Load() < 2; i++ {
+		if i > 100 {
+			t.Fatal("not reconnected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if n := connected.Load(); n != 2 {
+		t.Errorf("connected %d times, want 2", n)
+	}
+}
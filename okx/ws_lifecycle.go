@@ -0,0 +1,125 @@
+package okx
+
+import (
+	"github.com/pkg/errors"
+	"time"
+)
+
+// ErrClosed websocket已关闭
+var ErrClosed = errors.New("okx: websocket closed")
+
+// errServerClosed 交易所发送了正常关闭帧
+var errServerClosed = errors.New("okx: websocket closed by server")
+
+// closeTimeout 关闭时等待交易所确认的最长时间
+const closeTimeout = time.Second
+
+type ConnEvent int
+
+// 连接事件
+const (
+	EventConnected    ConnEvent = iota + 1 // 连接建立，登录和恢复订阅之前
+	EventDisconnected                      // 连接断开，err为断开的原因
+	EventReconnecting                      // 开始重连
+	EventResubscribed                      // 登录并恢复订阅完成
+	EventLoginFailed                       // 登录失败，err为失败原因，之后按退避时间重连并重新登录
+	EventThrottled                         // 触发建连或订阅限速，err包装了ErrThrottled并说明需要等待的时间
+)
+
+func (e ConnEvent) String() string {
+	switch e {
+	case EventConnected:
+		return "connected"
+	case EventDisconnected:
+		return "disconnected"
+	case EventReconnecting:
+		return "reconnecting"
+	case EventResubscribed:
+		return "resubscribed"
+	case EventLoginFailed:
+		return "loginFailed"
+	case EventThrottled:
+		return "throttled"
+	}
+
+	return "unknown"
+}
+
+// OnEvent 注册连接事件的回调，回调在连接或读取消息的goroutine中调用，不能长时间阻塞
+func (w *WebSocket) OnEvent(fn func(event ConnEvent, err error)) {
+	w.Lock()
+	defer w.Unlock()
+	w.listeners = append(w.listeners, fn)
+}
+
+func (w *WebSocket) emit(event ConnEvent, err error) {
+	w.RLock()
+	listeners := w.listeners
+	w.RUnlock()
+
+	for _, fn := range listeners {
+		fn(event, err)
+	}
+}
+
+// Close 关闭连接并停止自动重连，不等待读取消息和处理队列的goroutine退出，可以在处理函数中调用
+// 等待中的交易类操作返回ErrClosed，需要确认不再调用处理函数时在Close之后调用Wait
+func (w *WebSocket) Close() {
+	w.closeOnce.Do(func() {
+		w.cancel()
+		// 先关闭队列，避免读取消息的goroutine阻塞在已满的队列上
+		w.closeQueues()
+		w.loggedIn.Store(false)
+		w.failPending(ErrClosed)
+		go w.shutdown()
+	})
+}
+
+// Wait 等待Close完成，返回后不会再调用任何处理函数
+// 在处理函数中调用会一直阻塞
+func (w *WebSocket) Wait() {
+	<-w.closed
+}
+
+// shutdown 关闭连接并等待读取消息和处理队列的goroutine退出
+func (w *WebSocket) shutdown() {
+	defer close(w.closed)
+
+	w.RLock()
+	done := w.done
+	w.RUnlock()
+
+	if w.conn.IsConnected() {
+		// 发送关闭帧，交易所确认后读取会正常结束
+		w.conn.Shutdown(closeTimeout)
+	}
+
+	if done != nil {
+		select {
+		case <-done:
+		case <-time.After(closeTimeout):
+		}
+	}
+
+	w.conn.Close()
+	if done != nil {
+		<-done
+	}
+
+	w.workers.Wait()
+	w.failPending(ErrClosed)
+}
+
+// failPending 结束所有等待中的交易类操作
+func (w *WebSocket) failPending(err error) {
+	w.Lock()
+	defer w.Unlock()
+
+	for id, ch := range w.pending {
+		select {
+		case ch <- &opResponse{err: err}:
+		default:
+		}
+		delete(w.pending, id)
+	}
+}
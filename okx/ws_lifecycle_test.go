@@ -0,0 +1,209 @@
+package okx
+
+import (
+	"context"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLifecycle(t *testing.T) {
+	var mu sync.Mutex
+	var events []ConnEvent
+	record := func(event ConnEvent, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	wait := func(n int) []ConnEvent {
+		for i := 0; i < 200; i++ {
+			mu.Lock()
+			got := append([]ConnEvent(nil), events...)
+			mu.Unlock()
+			if len(got) >= n {
+				return got
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		t.Fatalf("events = %v, want %d events", events, n)
+		return nil
+	}
+
+	srv := newTestServer(t, func(req map[string]interface{}) []string {
+		switch req["op"] {
+		case "login":
+			return []string{`{"event":"login","code":"0","msg":""}`}
+		case "order":
+			// 不响应，断开连接
+			return []string{""}
+		}
+		return nil
+	})
+
+	ws := newTestWebSocket(t, srv)
+	ws.OnEvent(record)
+	ws.Start()
+
+	if got := wait(2); got[0] != EventConnected || got[1] != EventResubscribed {
+		t.Fatalf("events = %v", got)
+	}
+
+	// 连接断开时等待中的操作立即失败
+	_, err := ws.PlaceOrder(&PlaceOrderRequest{InstId: "BTC-USDT", TdMode: Cash, Side: Buy, OrdType: Market, Sz: "1"})
+	if err == nil || err == context.DeadlineExceeded {
+		t.Errorf("err = %v", err)
+	}
+
+	got := wait(6)
+	want := []ConnEvent{EventConnected, EventResubscribed, EventDisconnected, EventReconnecting, EventConnected, EventResubscribed}
+	for i, e := range want {
+		if got[i] != e {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ws.Close()
+		ws.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("close timeout")
+	}
+
+	if _, err := ws.CancelOrder("BTC-USDT", "1", ""); err != ErrClosed {
+		t.Errorf("err = %v, want ErrClosed", err)
+	}
+}
+
+func TestLoginRetry(t *testing.T) {
+	policy := loginRetryPolicy
+	loginRetryPolicy = RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	t.Cleanup(func() { loginRetryPolicy = policy })
+
+	var logins atomic.Int64
+	srv := newTestServer(t, func(req map[string]interface{}) []string {
+		if req["op"] == "login" && logins.Add(1) == 1 {
+			return []string{`{"event":"error","code":"60009","msg":"Login failed."}`}
+		}
+		return subscribeReply(req)
+	})
+
+	arg := SubscribeArg{Channel: "orders", InstType: "ANY"}
+	ws := newTestWebSocket(t, srv)
+	if err := ws.SubscribeArgs(arg); err != nil {
+		t.Fatal(err)
+	}
+	ws.Start()
+
+	// 第一次登录失败后重连，再次登录并恢复订阅
+	waitState(t, ws, arg, SubscribeActive)
+	if n := logins.Load(); n != 2 {
+		t.Errorf("logins = %d, want 2", n)
+	}
+}
+
+func TestCloseFromHandler(t *testing.T) {
+	srv := newTestServer(t, func(req map[string]interface{}) []string {
+		if req["op"] == "subscribe" {
+			return append(subscribeReply(req), `{"arg":{"channel":"tickers","instId":"BTC-USDT"},"data":[{"instId":"BTC-USDT","last":"1"}]}`)
+		}
+		return subscribeReply(req)
+	})
+
+	for _, queued := range []bool{false, true} {
+		ws := newTestWebSocket(t, srv)
+		if queued {
+			ws.SetDefaultQueue(QueueConfig{Size: 10})
+		}
+
+		// 在读取消息或处理队列的goroutine中关闭
+		ws.OnTickers("", func(push *Push[*Ticker]) {
+			ws.Close()
+		})
+		if err := ws.SubscribeArgs(SubscribeArg{Channel: TickersChannel, InstId: "BTC-USDT"}); err != nil {
+			t.Fatal(err)
+		}
+		ws.Start()
+
+		done := make(chan struct{})
+		go func() {
+			ws.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(3 * time.Second):
+			t.Fatalf("queued = %v: close from handler did not finish", queued)
+		}
+	}
+}
+
+func TestServerNormalClosure(t *testing.T) {
+	var conns atomic.Int64
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(rw, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		n := conns.Add(1)
+		for {
+			var req map[string]interface{}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+
+			for _, resp := range subscribeReply(req) {
+				_ = conn.WriteMessage(websocket.TextMessage, []byte(resp))
+			}
+
+			// 第一个连接登录后正常关闭
+			if n == 1 && req["op"] == "login" {
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	var mu sync.Mutex
+	var events []ConnEvent
+	ws := newTestWebSocket(t, srv)
+	ws.SetHeartbeat(0, 0)
+	ws.OnEvent(func(event ConnEvent, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	})
+	ws.Start()
+
+	for i := 0; conns.Load() < 2 || !ws.loggedIn.Load(); i++ {
+		if i > 300 {
+			t.Fatalf("conns = %d, not reconnected after normal closure", conns.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var disconnected bool
+	for _, e := range events {
+		disconnected = disconnected || e == EventDisconnected
+	}
+	if !disconnected {
+		t.Errorf("events = %v, want disconnected", events)
+	}
+}
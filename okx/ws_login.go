@@ -0,0 +1,148 @@
+package okx
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// loginTimeout 等待登录结果的最长时间
+const loginTimeout = 10 * time.Second
+
+// loginRetryPolicy 登录失败后重连的退避时间
+var loginRetryPolicy = RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}
+
+type LoginMsg struct {
+	Op   string      `json:"op"`
+	Args []*LoginArg `json:"args"`
+}
+
+type LoginArg struct {
+	ApiKey     string `json:"apiKey"`
+	Passphrase string `json:"passphrase"`
+	Timestamp  string `json:"timestamp"`
+	Sign       string `json:"sign"`
+}
+
+// BalanceAndPosition 账户余额和持仓频道的推送数据
+type BalanceAndPosition struct {
+	PTime     string `json:"pTime"`
+	EventType string `json:"eventType"` // 事件类型，如snapshot、delivered、filled
+	BalData   []struct {
+		Ccy     string `json:"ccy"`
+		CashBal string `json:"cashBal"` // 币种余额
+		UTime   string `json:"uTime"`
+	} `json:"balData"`
+	PosData []struct {
+		PosId    string `json:"posId"`
+		TradeId  string `json:"tradeId"`
+		InstId   string `json:"instId"`
+		InstType string `json:"instType"`
+		MgnMode  string `json:"mgnMode"`
+		PosSide  string `json:"posSide"`
+		Pos      string `json:"pos"`
+		Ccy      string `json:"ccy"`
+		PosCcy   string `json:"posCcy"`
+		AvgPx    string `json:"avgPx"`
+		UTime    string `json:"uTime"`
+	} `json:"posData"`
+	Trades []struct {
+		InstId  string `json:"instId"`
+		TradeId string `json:"tradeId"`
+	} `json:"trades"`
+}
+
+// SetCredentials 设置私有频道的登录凭证，需要在Start之前调用，每次连接建立后会先登录再恢复订阅
+func (w *WebSocket) SetCredentials(c *RestConfig) {
+	w.Lock()
+	defer w.Unlock()
+	w.auth = c
+}
+
+// loginMsg 生成登录请求，签名方式与rest接口相同，时间戳为秒
+func loginMsg(c *RestConfig, ts string) *LoginMsg {
+	return &LoginMsg{
+		Op: "login",
+		Args: []*LoginArg{{
+			ApiKey:     c.ApiKey,
+			Passphrase: c.Password,
+			Timestamp:  ts,
+			Sign:       c.getAccessSign("GET", "/users/self/verify", "", ts),
+		}},
+	}
+}
+
+// login 发送登录请求并等待结果
+func (w *WebSocket) login() error {
+	// 丢弃上一次连接遗留的结果
+	select {
+	case <-w.loginCh:
+	default:
+	}
+
+	w.loggingIn.Store(true)
+	defer w.loggingIn.Store(false)
+
+	if err := w.waitOp("login"); err != nil {
+		return err
+	}
+
+	ts := strconv.FormatInt(w.auth.now().Unix(), 10)
+	if err := w.sendWSMessage(loginMsg(w.auth, ts)); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(loginTimeout)
+	defer timer.Stop()
+
+	select {
+	case msg := <-w.loginCh:
+		if msg.Event != "login" || (msg.Code != "" && msg.Code != CodeSuccess) {
+			return fmt.Errorf("code: %s, msg: %s", msg.Code, msg.Msg)
+		}
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("login timeout")
+	case <-w.ctx.Done():
+		return w.ctx.Err()
+	}
+}
+
+// loginResult 在登录过程中转发登录结果，返回消息是否被处理
+func (w *WebSocket) loginResult(msg SocketMsg) bool {
+	if !w.loggingIn.Load() {
+		return false
+	}
+
+	select {
+	case w.loginCh <- msg:
+	default:
+	}
+
+	return true
+}
+
+// SubscribeOrders 订阅订单频道，instId为空时订阅instType下的所有产品
+func (w *WebSocket) SubscribeOrders(instType, instId string) error {
+	return w.SubscribeArgs(SubscribeArg{Channel: OrdersChannel, InstType: instType, InstId: instId})
+}
+
+// SubscribePositions 订阅持仓频道，instId为空时订阅instType下的所有产品
+func (w *WebSocket) SubscribePositions(instType, instId string) error {
+	return w.SubscribeArgs(SubscribeArg{Channel: PositionsChannel, InstType: instType, InstId: instId})
+}
+
+// SubscribeBalanceAndPosition 订阅账户余额和持仓频道
+func (w *WebSocket) SubscribeBalanceAndPosition() error {
+	return w.SubscribeArgs(SubscribeArg{Channel: BalanceAndPositionChannel})
+}
+
+// SubscribeAccount 订阅账户频道，ccy为空时订阅所有币种
+func (w *WebSocket) SubscribeAccount(ccy string) error {
+	return w.SubscribeArgs(SubscribeArg{Channel: AccountChannel, Ccy: ccy})
+}
+
+// OnBalanceAndPosition 注册balance_and_position频道的处理函数
+func (w *WebSocket) OnBalanceAndPosition(fn func(push *Push[*BalanceAndPosition])) {
+	on(w, BalanceAndPositionChannel, "", decodeJSON[*BalanceAndPosition], fn)
+}
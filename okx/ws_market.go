@@ -0,0 +1,115 @@
+package okx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MarkPrice 标记价格频道的推送数据
+type MarkPrice struct {
+	InstType string `json:"instType"`
+	InstId   string `json:"instId"`
+	MarkPx   string `json:"markPx"`
+	Ts       string `json:"ts"`
+}
+
+// IndexTicker 指数行情频道的推送数据
+type IndexTicker struct {
+	InstId  string `json:"instId"` // 指数，如BTC-USDT
+	IdxPx   string `json:"idxPx"`  // 最新指数价格
+	Open24h string `json:"open24h"`
+	High24h string `json:"high24h"`
+	Low24h  string `json:"low24h"`
+	SodUtc0 string `json:"sodUtc0"`
+	SodUtc8 string `json:"sodUtc8"`
+	Ts      string `json:"ts"`
+}
+
+// OpenInterest 持仓总量频道的推送数据
+type OpenInterest struct {
+	InstType string `json:"instType"`
+	InstId   string `json:"instId"`
+	Oi       string `json:"oi"`    // 持仓量，按张为单位
+	OiCcy    string `json:"oiCcy"` // 持仓量，按币为单位
+	OiUsd    string `json:"oiUsd"` // 持仓量，按美元为单位
+	Ts       string `json:"ts"`
+}
+
+// SubscribeTrades 订阅成交频道
+func (w *WebSocket) SubscribeTrades(instId string) error {
+	return w.SubscribeArgs(SubscribeArg{Channel: TradesChannel, InstId: instId})
+}
+
+// SubscribeTradesAll 订阅逐笔成交频道，需要连接业务频道地址
+func (w *WebSocket) SubscribeTradesAll(instId string) error {
+	return w.SubscribeArgs(SubscribeArg{Channel: TradesAllChannel, InstId: instId})
+}
+
+// SubscribeTickers 订阅行情频道
+func (w *WebSocket) SubscribeTickers(instId string) error {
+	return w.SubscribeArgs(SubscribeArg{Channel: TickersChannel, InstId: instId})
+}
+
+// SubscribeCandles 订阅k线频道，bar如Min、H，需要使用业务频道地址
+func (w *WebSocket) SubscribeCandles(bar, instId string) error {
+	return w.SubscribeArgs(SubscribeArg{Channel: CandleChannel + bar, InstId: instId})
+}
+
+// SubscribeMarkPrice 订阅标记价格频道
+func (w *WebSocket) SubscribeMarkPrice(instId string) error {
+	return w.SubscribeArgs(SubscribeArg{Channel: MarkPriceChannel, InstId: instId})
+}
+
+// SubscribeIndexTickers 订阅指数行情频道，instId为指数，如BTC-USDT
+func (w *WebSocket) SubscribeIndexTickers(instId string) error {
+	return w.SubscribeArgs(SubscribeArg{Channel: IndexTickersChannel, InstId: instId})
+}
+
+// SubscribeFundingRate 订阅资金费率频道，仅适用于永续合约
+func (w *WebSocket) SubscribeFundingRate(instId string) error {
+	return w.SubscribeArgs(SubscribeArg{Channel: FundingRateChannel, InstId: instId})
+}
+
+// SubscribeOpenInterest 订阅持仓总量频道
+func (w *WebSocket) SubscribeOpenInterest(instId string) error {
+	return w.SubscribeArgs(SubscribeArg{Channel: OpenInterestChannel, InstId: instId})
+}
+
+// OnMarkPrice 注册mark-price频道的处理函数
+func (w *WebSocket) OnMarkPrice(instId string, fn func(push *Push[*MarkPrice])) {
+	on(w, MarkPriceChannel, instId, decodeJSON[*MarkPrice], fn)
+}
+
+// OnIndexTickers 注册index-tickers频道的处理函数
+func (w *WebSocket) OnIndexTickers(instId string, fn func(push *Push[*IndexTicker])) {
+	on(w, IndexTickersChannel, instId, decodeJSON[*IndexTicker], fn)
+}
+
+// OnFundingRate 注册funding-rate频道的处理函数
+func (w *WebSocket) OnFundingRate(instId string, fn func(push *Push[*FundingRate])) {
+	on(w, FundingRateChannel, instId, decodeJSON[*FundingRate], fn)
+}
+
+// OnOpenInterest 注册open-interest频道的处理函数
+func (w *WebSocket) OnOpenInterest(instId string, fn func(push *Push[*OpenInterest])) {
+	on(w, OpenInterestChannel, instId, decodeJSON[*OpenInterest], fn)
+}
+
+// checkChannelURL 检查频道是否订阅在正确的地址上，无法识别的频道和地址不检查
+func (w *WebSocket) checkChannelURL(channel string) error {
+	kind := channelKind(channel)
+	if kind == "" {
+		return nil
+	}
+
+	i := strings.Index(w.wsURL, "/ws/v5/")
+	if i < 0 {
+		return nil
+	}
+
+	if !strings.HasPrefix(w.wsURL[i+len("/ws/v5/"):], kind) {
+		return fmt.Errorf("channel %s must be subscribed on the %s endpoint, see Endpoint.ChannelURL", channel, kind)
+	}
+
+	return nil
+}
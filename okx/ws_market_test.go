@@ -0,0 +1,76 @@
+package okx
+
+import (
+	"testing"
+)
+
+func TestChannelURL(t *testing.T) {
+	e := DefaultEndpoint(false)
+	cases := map[string]string{
+		CandleChannel + Min:        SocketBusinessUrl,
+		MarkPriceCandleChannel + H: SocketBusinessUrl,
+		MarkPriceChannel:           SocketPubUrl,
+		TickersChannel:             SocketPubUrl,
+		OrdersChannel:              SocketPriGlobalUrl,
+		BalanceAndPositionChannel:  SocketPriGlobalUrl,
+		IndexTickersChannel:        SocketPubUrl,
+		TradesAllChannel:           SocketBusinessUrl,
+		TradesChannel:              SocketPubUrl,
+	}
+
+	for channel, want := range cases {
+		if got := e.ChannelURL(channel); got != want {
+			t.Errorf("ChannelURL(%s) = %s, want %s", channel, got, want)
+		}
+	}
+
+	ws := InitWebSocket(SocketPubUrl)
+	if err := ws.SubscribeCandles(Min, "BTC-USDT"); err == nil {
+		t.Error("expected error subscribing candles on the public endpoint")
+	}
+
+	if err := ws.SubscribeMarkPrice("BTC-USDT-SWAP"); err != nil {
+		t.Error(err)
+	}
+
+	ws = InitWebSocket(SocketSimBusinessUrl)
+	if err := ws.SubscribeCandles(Min, "BTC-USDT"); err != nil {
+		t.Error(err)
+	}
+
+	// 没有列出的频道不检查
+	if err := ws.SubscribeArgs(SubscribeArg{Channel: "orders-algo", InstType: "ANY"}); err != nil {
+		t.Error(err)
+	}
+
+	ws = InitWebSocket(SocketPriGlobalUrl)
+	if err := ws.SubscribeArgs(SubscribeArg{Channel: "fills"}, SubscribeArg{Channel: "liquidation-warning", InstType: "ANY"}); err != nil {
+		t.Error(err)
+	}
+	if err := ws.SubscribeArgs(SubscribeArg{Channel: TickersChannel, InstId: "BTC-USDT"}); err == nil {
+		t.Error("expected error subscribing tickers on the private endpoint")
+	}
+}
+
+func TestMarketStreams(t *testing.T) {
+	ws := InitWebSocket(SocketPubUrl)
+
+	var markPx, fundingRate, oi string
+	ws.OnMarkPrice("BTC-USDT-SWAP", func(push *Push[*MarkPrice]) {
+		markPx = push.Data[0].MarkPx
+	})
+	ws.OnFundingRate("", func(push *Push[*FundingRate]) {
+		fundingRate = push.Data[0].FundingRate + "/" + push.Data[0].Premium
+	})
+	ws.OnOpenInterest("BTC-USDT-SWAP", func(push *Push[*OpenInterest]) {
+		oi = push.Data[0].Oi
+	})
+
+	ws.handle([]byte(`{"arg":{"channel":"mark-price","instId":"BTC-USDT-SWAP"},"data":[{"instType":"SWAP","instId":"BTC-USDT-SWAP","markPx":"42310.6","ts":"1630049139746"}]}`))
+	ws.handle([]byte(`{"arg":{"channel":"funding-rate","instId":"BTC-USDT-SWAP"},"data":[{"fundingRate":"0.0001","premium":"-0.0001","instId":"BTC-USDT-SWAP","instType":"SWAP"}]}`))
+	ws.handle([]byte(`{"arg":{"channel":"open-interest","instId":"BTC-USDT-SWAP"},"data":[{"instId":"BTC-USDT-SWAP","instType":"SWAP","oi":"2216113.01","oiCcy":"22161.1301","ts":"1640081100000"}]}`))
+
+	if markPx != "42310.6" || fundingRate != "0.0001/-0.0001" || oi != "2216113.01" {
+		t.Errorf("markPx = %s, fundingRate = %s, oi = %s", markPx, fundingRate, oi)
+	}
+}
@@ -0,0 +1,294 @@
+package okx
+
+import (
+	"fmt"
+	"github.com/zeromicro/go-zero/core/logx"
+	"sync"
+)
+
+// DefaultPoolLimit 连接池中每个连接默认的最大订阅数
+const DefaultPoolLimit = 100
+
+// WebSocketPool 把订阅分散到多个连接上，每个连接的订阅数不超过limit
+// 处理函数通过Register注册到每个连接上，订阅在哪个连接上对应用透明
+type WebSocketPool struct {
+	mu sync.Mutex
+
+	url     string
+	limit   int
+	started bool
+	closed  bool
+
+	conns  []*WebSocket
+	owner  map[SubscribeArg]*WebSocket
+	load   map[*WebSocket]int
+	setups []func(ws *WebSocket) // 对每个连接的配置和处理函数注册，新建连接时重放
+}
+
+// NewWebSocketPool 返回url的连接池，limit小于等于0时使用DefaultPoolLimit
+func NewWebSocketPool(url string, limit int) *WebSocketPool {
+	if limit <= 0 {
+		limit = DefaultPoolLimit
+	}
+
+	return &WebSocketPool{
+		url:   url,
+		limit: limit,
+		owner: make(map[SubscribeArg]*WebSocket),
+		load:  make(map[*WebSocket]int),
+	}
+}
+
+// Register 在每个连接上执行fn，包括之后新建的连接，用于设置代理、登录凭证和注册处理函数
+// 不同连接的处理函数会并发调用
+//
+//	pool.Register(func(ws *WebSocket) {
+//		ws.OnBooks(BooksChannel, "", handler)
+//	})
+func (p *WebSocketPool) Register(fn func(ws *WebSocket)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.setups = append(p.setups, fn)
+	for _, ws := range p.conns {
+		fn(ws)
+	}
+}
+
+// Start 启动所有连接，之后新建的连接会自动启动
+func (p *WebSocketPool) Start() {
+	p.mu.Lock()
+	p.started = true
+	conns := append([]*WebSocket(nil), p.conns...)
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, ws := range conns {
+		wg.Add(1)
+		go func(ws *WebSocket) {
+			defer wg.Done()
+			ws.Start()
+		}(ws)
+	}
+	wg.Wait()
+}
+
+// Close 关闭所有连接，不等待连接退出，见WebSocket.Close
+func (p *WebSocketPool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	conns := append([]*WebSocket(nil), p.conns...)
+	p.mu.Unlock()
+
+	for _, ws := range conns {
+		ws.Close()
+	}
+}
+
+// Wait 等待所有连接关闭完成，在Close之后调用
+func (p *WebSocketPool) Wait() {
+	p.mu.Lock()
+	conns := append([]*WebSocket(nil), p.conns...)
+	p.mu.Unlock()
+
+	for _, ws := range conns {
+		ws.Wait()
+	}
+}
+
+// Conns 返回当前的连接数
+func (p *WebSocketPool) Conns() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.conns)
+}
+
+// SubscribeArgs 订阅频道，每个频道分配到订阅数最少且未满的连接，所有连接都满时新建连接
+func (p *WebSocketPool) SubscribeArgs(args ...SubscribeArg) error {
+	probe := &WebSocket{wsURL: p.url}
+	for _, arg := range args {
+		if err := probe.checkChannelURL(arg.Channel); err != nil {
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrClosed
+	}
+
+	assigned := make(map[*WebSocket][]SubscribeArg)
+	var created []*WebSocket
+	for _, arg := range args {
+		if _, ok := p.owner[arg]; ok {
+			continue
+		}
+
+		ws := p.leastLoaded()
+		if ws == nil {
+			ws = p.newConn()
+			created = append(created, ws)
+		}
+
+		p.owner[arg] = ws
+		p.load[ws]++
+		assigned[ws] = append(assigned[ws], arg)
+	}
+	started := p.started
+	p.mu.Unlock()
+
+	if started {
+		for _, ws := range created {
+			go ws.Start()
+		}
+	}
+
+	for ws, args := range assigned {
+		if err := ws.SubscribeArgs(args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Unsubscribe 取消订阅
+func (p *WebSocketPool) Unsubscribe(args ...SubscribeArg) error {
+	p.mu.Lock()
+	assigned := make(map[*WebSocket][]SubscribeArg)
+	for _, arg := range args {
+		if ws, ok := p.owner[arg]; ok {
+			delete(p.owner, arg)
+			p.load[ws]--
+			assigned[ws] = append(assigned[ws], arg)
+		}
+	}
+	p.mu.Unlock()
+
+	for ws, args := range assigned {
+		if err := ws.Unsubscribe(args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Subscriptions 返回所有连接上的订阅状态
+func (p *WebSocketPool) Subscriptions() []Subscription {
+	p.mu.Lock()
+	conns := append([]*WebSocket(nil), p.conns...)
+	p.mu.Unlock()
+
+	var subs []Subscription
+	for _, ws := range conns {
+		subs = append(subs, ws.Subscriptions()...)
+	}
+
+	return subs
+}
+
+// Rebalance 在连接之间移动订阅，使各连接的订阅数相差不超过1，订阅失败的频道会优先移动
+// 连接重连并恢复订阅后会自动调用
+func (p *WebSocketPool) Rebalance() error {
+	type move struct {
+		arg      SubscribeArg
+		from, to *WebSocket
+	}
+
+	p.mu.Lock()
+	var moves []move
+	for {
+		from, to := p.mostLoaded(), p.leastLoaded()
+		if from == nil || to == nil || p.load[from]-p.load[to] <= 1 {
+			break
+		}
+
+		arg := p.pick(from)
+		p.owner[arg] = to
+		p.load[from]--
+		p.load[to]++
+		moves = append(moves, move{arg: arg, from: from, to: to})
+	}
+	p.mu.Unlock()
+
+	for _, m := range moves {
+		if err := m.from.Unsubscribe(m.arg); err != nil {
+			return fmt.Errorf("move %v: %w", m.arg, err)
+		}
+		if err := m.to.SubscribeArgs(m.arg); err != nil {
+			return fmt.Errorf("move %v: %w", m.arg, err)
+		}
+	}
+
+	return nil
+}
+
+// newConn 新建连接并重放配置，调用时需要持有p.mu
+func (p *WebSocketPool) newConn() *WebSocket {
+	ws := InitWebSocket(p.url)
+	for _, fn := range p.setups {
+		fn(ws)
+	}
+
+	ws.OnEvent(func(event ConnEvent, err error) {
+		if event == EventResubscribed {
+			go p.rebalance()
+		}
+	})
+
+	p.conns = append(p.conns, ws)
+	p.load[ws] = 0
+	return ws
+}
+
+func (p *WebSocketPool) rebalance() {
+	if err := p.Rebalance(); err != nil {
+		logx.Errorf("[ws] rebalance failed: %v", err)
+	}
+}
+
+// leastLoaded 返回订阅数最少且未满的连接，没有时返回nil
+func (p *WebSocketPool) leastLoaded() *WebSocket {
+	var best *WebSocket
+	for _, ws := range p.conns {
+		if p.load[ws] < p.limit && (best == nil || p.load[ws] < p.load[best]) {
+			best = ws
+		}
+	}
+
+	return best
+}
+
+func (p *WebSocketPool) mostLoaded() *WebSocket {
+	var best *WebSocket
+	for _, ws := range p.conns {
+		if best == nil || p.load[ws] > p.load[best] {
+			best = ws
+		}
+	}
+
+	return best
+}
+
+// pick 选择ws上要移走的订阅，优先选择订阅失败的频道
+func (p *WebSocketPool) pick(ws *WebSocket) SubscribeArg {
+	var pick SubscribeArg
+	found := false
+	for arg, owner := range p.owner {
+		if owner != ws {
+			continue
+		}
+
+		if sub, ok := ws.Subscription(arg); ok && sub.State == SubscribeFailed {
+			return arg
+		}
+
+		if !found {
+			pick, found = arg, true
+		}
+	}
+
+	return pick
+}
@@ -0,0 +1,94 @@
+package okx
+
+import (
+	"fmt"
+	"testing"
+)
+
+func poolLoads(p *WebSocketPool) []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	loads := make([]int, len(p.conns))
+	for i, ws := range p.conns {
+		loads[i] = p.load[ws]
+		if n := len(ws.Subscriptions()); n != loads[i] {
+			panic(fmt.Sprintf("conn %d has %d subscriptions, load %d", i, n, loads[i]))
+		}
+	}
+
+	return loads
+}
+
+func TestWebSocketPool(t *testing.T) {
+	pool := NewWebSocketPool(SocketPubUrl, 2)
+
+	registered := 0
+	var trades []string
+	pool.Register(func(ws *WebSocket) {
+		registered++
+		ws.OnTrades("", func(push *Push[*Trade]) {
+			trades = append(trades, push.Arg.InstId)
+		})
+	})
+
+	var args []SubscribeArg
+	for i := 0; i < 5; i++ {
+		args = append(args, SubscribeArg{Channel: TradesChannel, InstId: fmt.Sprintf("INST-%d", i)})
+	}
+
+	if err := pool.SubscribeArgs(args...); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fmt.Sprint(poolLoads(pool)); got != "[2 2 1]" {
+		t.Fatalf("loads = %s", got)
+	}
+
+	if registered != 3 {
+		t.Errorf("registered on %d conns, want 3", registered)
+	}
+
+	// 推送从任意连接到达都由同一个处理函数处理
+	pool.conns[2].handle([]byte(`{"arg":{"channel":"trades","instId":"INST-4"},"data":[{"instId":"INST-4"}]}`))
+	if len(trades) != 1 || trades[0] != "INST-4" {
+		t.Errorf("trades = %v", trades)
+	}
+
+	// 取消第一个连接上的订阅后重新平衡
+	if err := pool.Unsubscribe(args[0], args[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pool.Rebalance(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fmt.Sprint(poolLoads(pool)); got != "[1 1 1]" {
+		t.Errorf("loads after rebalance = %s", got)
+	}
+
+	if n := len(pool.Subscriptions()); n != 3 {
+		t.Errorf("subscriptions = %d, want 3", n)
+	}
+}
+
+func TestWebSocketPoolChannelURL(t *testing.T) {
+	pool := NewWebSocketPool(SocketPriGlobalUrl, 10)
+	defer pool.Close()
+
+	// 没有列出的私有频道不检查地址
+	if err := pool.SubscribeArgs(SubscribeArg{Channel: "fills"}, SubscribeArg{Channel: "liquidation-warning", InstType: "ANY"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pool.SubscribeArgs(SubscribeArg{Channel: TickersChannel, InstId: "BTC-USDT"}); err == nil {
+		t.Error("expected error subscribing tickers on the private endpoint")
+	}
+
+	business := NewWebSocketPool(SocketSimBusinessUrl, 10)
+	defer business.Close()
+	if err := business.SubscribeArgs(SubscribeArg{Channel: "orders-algo", InstType: "ANY"}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,244 @@
+package okx
+
+import (
+	"sync"
+)
+
+type OverflowPolicy int
+
+// 队列满时的处理方式
+const (
+	OverflowBlock      OverflowPolicy = iota + 1 // 阻塞读取，直到队列有空位，可能导致交易所断开连接
+	OverflowDropOldest                           // 丢弃最旧的消息
+	OverflowCoalesce                             // 只保留最新的消息，适用于tickers、books5、bbo-tbt等每次推送完整数据的频道，不能用于增量深度频道
+)
+
+// QueueConfig 消息队列的配置
+type QueueConfig struct {
+	Size   int // 队列长度，小于等于0时为1
+	Policy OverflowPolicy
+}
+
+// QueueStats 消息队列的统计数据
+type QueueStats struct {
+	Len       int    // 当前排队的消息数
+	Enqueued  uint64 // 进入队列的消息数
+	Delivered uint64 // 已交给处理函数的消息数
+	Dropped   uint64 // 因队列满被丢弃或合并的消息数
+}
+
+// msgQueue 一个订阅的有界消息队列，由单独的goroutine按顺序交给处理函数
+type msgQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	cfg    QueueConfig
+	items  []*PushMsg
+	stats  QueueStats
+	closed bool
+}
+
+// SetQueue 为arg的推送设置有界队列，推送在单独的goroutine中处理，避免慢的处理函数阻塞读取
+// 未设置队列的订阅使用SetDefaultQueue的配置，都没有设置时在读取消息的goroutine中同步处理
+// 队列在收到第一条推送时创建，之后修改配置不会生效，取消订阅后删除队列，再次收到推送时按新的配置创建
+func (w *WebSocket) SetQueue(arg SubscribeArg, cfg QueueConfig) {
+	w.Lock()
+	defer w.Unlock()
+	w.queueCfg[arg] = cfg
+}
+
+// SetDefaultQueue 为所有未单独设置队列的订阅设置有界队列
+func (w *WebSocket) SetDefaultQueue(cfg QueueConfig) {
+	w.Lock()
+	defer w.Unlock()
+	w.defaultQueue = &cfg
+}
+
+// QueueStats 返回arg的队列统计，没有队列时返回false
+func (w *WebSocket) QueueStats(arg SubscribeArg) (QueueStats, bool) {
+	w.RLock()
+	q, ok := w.queues[arg]
+	w.RUnlock()
+
+	if !ok {
+		return QueueStats{}, false
+	}
+
+	return q.snapshot(), true
+}
+
+// AllQueueStats 返回所有队列的统计
+func (w *WebSocket) AllQueueStats() map[SubscribeArg]QueueStats {
+	w.RLock()
+	defer w.RUnlock()
+
+	stats := make(map[SubscribeArg]QueueStats, len(w.queues))
+	for arg, q := range w.queues {
+		stats[arg] = q.snapshot()
+	}
+
+	return stats
+}
+
+// deliver 把推送放入arg的队列，没有配置队列时直接处理
+func (w *WebSocket) deliver(msg *PushMsg) {
+	q := w.queue(msg.Arg)
+	if q == nil {
+		w.dispatch(msg)
+		return
+	}
+
+	q.push(msg)
+}
+
+// queue 返回arg的队列，第一次使用时创建队列和处理消息的goroutine
+func (w *WebSocket) queue(arg SubscribeArg) *msgQueue {
+	w.RLock()
+	q, ok := w.queues[arg]
+	w.RUnlock()
+	if ok {
+		return q
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	if q, ok = w.queues[arg]; ok {
+		return q
+	}
+
+	// 已关闭时不再创建队列
+	if w.ctx.Err() != nil {
+		return nil
+	}
+
+	cfg, ok := w.queueCfg[arg]
+	if !ok {
+		if w.defaultQueue == nil {
+			return nil
+		}
+		cfg = *w.defaultQueue
+	}
+
+	if cfg.Size <= 0 {
+		cfg.Size = 1
+	}
+
+	q = &msgQueue{cfg: cfg}
+	q.cond = sync.NewCond(&q.mu)
+	w.queues[arg] = q
+
+	w.workers.Add(1)
+	go func() {
+		defer w.workers.Done()
+		for {
+			msg, ok := q.pop()
+			if !ok {
+				return
+			}
+			w.dispatch(msg)
+		}
+	}()
+
+	return q
+}
+
+// removeQueues 关闭并删除取消订阅的队列，频道没有其他订阅时删除该频道的所有队列，需要持有写锁
+// 未处理的消息会被丢弃，之后再收到推送时重新创建队列
+func (w *WebSocket) removeQueues(args []SubscribeArg) {
+	removed := make(map[SubscribeArg]bool, len(args))
+	channels := make(map[string]bool)
+	for _, arg := range args {
+		removed[arg] = true
+		channels[arg.Channel] = true
+	}
+
+	// 推送的arg可能与订阅时不同，频道还有其他订阅时只删除取消订阅的arg的队列
+	for arg := range w.subscriptions {
+		delete(channels, arg.Channel)
+	}
+
+	for arg, q := range w.queues {
+		if removed[arg] || channels[arg.Channel] {
+			q.close()
+			delete(w.queues, arg)
+		}
+	}
+}
+
+// closeQueues 关闭所有队列，未处理的消息会被丢弃，处理消息的goroutine在当前的处理函数返回后退出
+func (w *WebSocket) closeQueues() {
+	w.RLock()
+	defer w.RUnlock()
+
+	for _, q := range w.queues {
+		q.close()
+	}
+}
+
+func (q *msgQueue) push(msg *PushMsg) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+
+	q.stats.Enqueued++
+	switch q.cfg.Policy {
+	case OverflowCoalesce:
+		q.stats.Dropped += uint64(len(q.items))
+		q.items = q.items[:0]
+	case OverflowDropOldest:
+		if len(q.items) >= q.cfg.Size {
+			q.items = q.items[1:]
+			q.stats.Dropped++
+		}
+	default:
+		for len(q.items) >= q.cfg.Size && !q.closed {
+			q.cond.Wait()
+		}
+		if q.closed {
+			return
+		}
+	}
+
+	q.items = append(q.items, msg)
+	q.cond.Broadcast()
+}
+
+func (q *msgQueue) pop() (*PushMsg, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+
+	if q.closed {
+		return nil, false
+	}
+
+	msg := q.items[0]
+	q.items[0] = nil
+	q.items = q.items[1:]
+	q.stats.Delivered++
+	q.cond.Broadcast()
+	return msg, true
+}
+
+func (q *msgQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.items = nil
+	q.cond.Broadcast()
+}
+
+func (q *msgQueue) snapshot() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := q.stats
+	stats.Len = len(q.items)
+	return stats
+}
@@ -0,0 +1,241 @@
+package okx
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newQueueTest 返回在收到第一条推送时阻塞的WebSocket，started在第一条推送开始处理时关闭，关闭gate后继续处理
+func newQueueTest(t *testing.T, cfg QueueConfig) (ws *WebSocket, arg SubscribeArg, started, gate chan struct{}, got chan string) {
+	ws = InitWebSocket(SocketPubUrl)
+	arg = SubscribeArg{Channel: TickersChannel, InstId: "BTC-USDT"}
+	ws.SetQueue(arg, cfg)
+
+	started, gate, got = make(chan struct{}), make(chan struct{}), make(chan string, 10)
+	ws.OnTickers("BTC-USDT", func(push *Push[*Ticker]) {
+		if push.Data[0].Last == "1" {
+			close(started)
+			<-gate
+		}
+		got <- push.Data[0].Last
+	})
+
+	return
+}
+
+func pushTicker(ws *WebSocket, last int) {
+	ws.handle([]byte(fmt.Sprintf(`{"arg":{"channel":"tickers","instId":"BTC-USDT"},"data":[{"instId":"BTC-USDT","last":"%d"}]}`, last)))
+}
+
+func receive(t *testing.T, got chan string, n int) []string {
+	var ret []string
+	for i := 0; i < n; i++ {
+		select {
+		case last := <-got:
+			ret = append(ret, last)
+		case <-time.After(time.Second):
+			t.Fatalf("received %v, want %d messages", ret, n)
+		}
+	}
+
+	return ret
+}
+
+func TestQueueDropOldest(t *testing.T) {
+	ws, arg, started, gate, got := newQueueTest(t, QueueConfig{Size: 2, Policy: OverflowDropOldest})
+	defer ws.Close()
+
+	pushTicker(ws, 1)
+	<-started
+	for i := 2; i <= 5; i++ {
+		pushTicker(ws, i)
+	}
+
+	stats, ok := ws.QueueStats(arg)
+	if !ok || stats.Enqueued != 5 || stats.Dropped != 2 || stats.Len != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	close(gate)
+	if ret := fmt.Sprint(receive(t, got, 3)); ret != "[1 4 5]" {
+		t.Errorf("received %s", ret)
+	}
+}
+
+func TestQueueCoalesce(t *testing.T) {
+	ws, arg, started, gate, got := newQueueTest(t, QueueConfig{Policy: OverflowCoalesce})
+	defer ws.Close()
+
+	pushTicker(ws, 1)
+	<-started
+	for i := 2; i <= 4; i++ {
+		pushTicker(ws, i)
+	}
+
+	stats, _ := ws.QueueStats(arg)
+	if stats.Dropped != 2 || stats.Len != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	close(gate)
+	if ret := fmt.Sprint(receive(t, got, 2)); ret != "[1 4]" {
+		t.Errorf("received %s", ret)
+	}
+
+	stats = ws.AllQueueStats()[arg]
+	if stats.Delivered != 2 || stats.Len != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestQueueBlock(t *testing.T) {
+	ws, arg, started, gate, got := newQueueTest(t, QueueConfig{Size: 1, Policy: OverflowBlock})
+	defer ws.Close()
+
+	pushTicker(ws, 1)
+	<-started
+	pushTicker(ws, 2)
+
+	pushed := make(chan struct{})
+	go func() {
+		pushTicker(ws, 3)
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("push should block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(gate)
+	<-pushed
+	if ret := fmt.Sprint(receive(t, got, 3)); ret != "[1 2 3]" {
+		t.Errorf("received %s", ret)
+	}
+
+	if stats, _ := ws.QueueStats(arg); stats.Dropped != 0 || stats.Enqueued != 3 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestQueueClose(t *testing.T) {
+	ws, _, started, gate, _ := newQueueTest(t, QueueConfig{Size: 1, Policy: OverflowBlock})
+
+	pushTicker(ws, 1)
+	<-started
+	pushTicker(ws, 2)
+
+	pushed := make(chan struct{})
+	go func() {
+		pushTicker(ws, 3)
+		close(pushed)
+	}()
+
+	ws.Close()
+	closed := make(chan struct{})
+	go func() {
+		ws.Wait()
+		close(closed)
+	}()
+
+	// 阻塞的推送在队列关闭后返回，Wait等待正在执行的处理函数结束
+	select {
+	case <-pushed:
+	case <-time.After(time.Second):
+		t.Fatal("blocked push not released by Close")
+	}
+
+	select {
+	case <-closed:
+		t.Fatal("Wait returned before the handler finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(gate)
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return")
+	}
+}
+
+func TestQueueDefault(t *testing.T) {
+	ws := InitWebSocket(SocketPubUrl)
+	defer ws.Close()
+
+	var last string
+	ws.OnTickers("", func(push *Push[*Ticker]) {
+		last = push.Data[0].Last
+	})
+
+	// 未设置队列时同步处理
+	pushTicker(ws, 1)
+	if _, ok := ws.QueueStats(SubscribeArg{Channel: TickersChannel, InstId: "BTC-USDT"}); ok || last != "1" {
+		t.Errorf("last = %s, queue created: %v", last, ok)
+	}
+
+	done := make(chan struct{})
+	ws.OnTrades("", func(push *Push[*Trade]) {
+		close(done)
+	})
+	ws.SetDefaultQueue(QueueConfig{Size: 10, Policy: OverflowDropOldest})
+	ws.handle([]byte(`{"arg":{"channel":"trades","instId":"BTC-USDT"},"data":[{"instId":"BTC-USDT","px":"1","sz":"1"}]}`))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("trade not delivered")
+	}
+
+	if stats, ok := ws.QueueStats(SubscribeArg{Channel: TradesChannel, InstId: "BTC-USDT"}); !ok || stats.Delivered != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestQueueUnsubscribe(t *testing.T) {
+	ws, arg, started, gate, got := newQueueTest(t, QueueConfig{Size: 10, Policy: OverflowDropOldest})
+	if err := ws.SubscribeArgs(arg); err != nil {
+		t.Fatal(err)
+	}
+
+	pushTicker(ws, 1)
+	<-started
+	pushTicker(ws, 2)
+
+	// 取消订阅后队列被删除，未处理的消息被丢弃
+	if err := ws.Unsubscribe(arg); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ws.QueueStats(arg); ok {
+		t.Error("queue not removed after unsubscribe")
+	}
+
+	close(gate)
+	if last := receive(t, got, 1); last[0] != "1" {
+		t.Errorf("received %v", last)
+	}
+
+	// 再次收到推送时重新创建队列
+	pushTicker(ws, 3)
+	if last := receive(t, got, 1); last[0] != "3" {
+		t.Errorf("received %v", last)
+	}
+	if stats, ok := ws.QueueStats(arg); !ok || stats.Enqueued != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	ws.Close()
+	closed := make(chan struct{})
+	go func() {
+		ws.Wait()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return")
+	}
+}
@@ -0,0 +1,213 @@
+package okx
+
+import (
+	"github.com/zeromicro/go-zero/core/logx"
+	"strconv"
+)
+
+type SubscriptionState int
+
+// 订阅状态
+const (
+	SubscribePending SubscriptionState = iota + 1 // 已发送或等待连接，尚未确认
+	SubscribeActive                               // 交易所已确认
+	SubscribeFailed                               // 交易所返回错误，不会自动重试
+)
+
+func (s SubscriptionState) String() string {
+	switch s {
+	case SubscribePending:
+		return "pending"
+	case SubscribeActive:
+		return "active"
+	case SubscribeFailed:
+		return "failed"
+	}
+
+	return "unknown"
+}
+
+// Subscription 订阅及其当前状态
+type Subscription struct {
+	Arg   SubscribeArg
+	State SubscriptionState
+	Code  string // 订阅失败时的错误码
+	Msg   string // 订阅失败时的错误信息
+}
+
+// SubscribeArgs 订阅频道，连接断开重连后会自动恢复订阅
+// 未连接或未登录时只记录订阅，连接建立并登录成功后统一发送，频道与连接地址不匹配时返回错误
+// 频道较多时分多次发送，超过OKX每个连接的操作次数限制时阻塞等待并产生EventThrottled事件
+func (w *WebSocket) SubscribeArgs(args ...SubscribeArg) error {
+	for _, arg := range args {
+		if err := w.checkChannelURL(arg.Channel); err != nil {
+			return err
+		}
+	}
+
+	w.Lock()
+	for _, arg := range args {
+		w.subscriptions[arg] = &Subscription{Arg: arg, State: SubscribePending}
+	}
+	w.Unlock()
+
+	if !w.conn.IsConnected() || (w.auth != nil && !w.loggedIn.Load()) {
+		return nil
+	}
+
+	return w.sendSubscribe("subscribe", args)
+}
+
+// Unsubscribe 取消订阅，已注册的处理函数不会被移除
+func (w *WebSocket) Unsubscribe(args ...SubscribeArg) error {
+	w.Lock()
+	for _, arg := range args {
+		delete(w.subscriptions, arg)
+	}
+	w.removeQueues(args)
+	w.Unlock()
+
+	if !w.conn.IsConnected() {
+		return nil
+	}
+
+	return w.sendSubscribe("unsubscribe", args)
+}
+
+// Subscription 返回arg的订阅状态，未订阅时返回false
+func (w *WebSocket) Subscription(arg SubscribeArg) (Subscription, bool) {
+	w.RLock()
+	defer w.RUnlock()
+
+	sub, ok := w.subscriptions[arg]
+	if !ok {
+		return Subscription{}, false
+	}
+
+	return *sub, true
+}
+
+// Subscriptions 返回所有订阅的状态
+func (w *WebSocket) Subscriptions() []Subscription {
+	w.RLock()
+	defer w.RUnlock()
+
+	subs := make([]Subscription, 0, len(w.subscriptions))
+	for _, sub := range w.subscriptions {
+		subs = append(subs, *sub)
+	}
+
+	return subs
+}
+
+// sendSubscribe 发送订阅或取消订阅请求，订阅请求的id用于关联错误响应
+// 频道较多时按请求长度限制分成多次发送，每次发送前按连接的操作次数限速
+func (w *WebSocket) sendSubscribe(op string, args []SubscribeArg) error {
+	for _, chunk := range chunkArgs(args, maxSubscribeSize) {
+		if err := w.waitOp(op); err != nil {
+			return err
+		}
+
+		msg := &SubscribeMsg{Id: strconv.FormatInt(w.nextId.Add(1), 10), Op: op, Args: chunk}
+		if op == "subscribe" {
+			w.Lock()
+			w.subIds[msg.Id] = chunk
+			w.Unlock()
+		}
+
+		if err := w.sendWSMessage(msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resubscribe 重新订阅arg，用于获取新的快照
+func (w *WebSocket) resubscribe(arg SubscribeArg) error {
+	if err := w.sendSubscribe("unsubscribe", []SubscribeArg{arg}); err != nil {
+		return err
+	}
+
+	w.setState(arg, SubscribePending, "", "")
+	return w.sendSubscribe("subscribe", []SubscribeArg{arg})
+}
+
+// resubscribeAll 连接建立后恢复所有订阅，包括之前失败的订阅
+func (w *WebSocket) resubscribeAll() error {
+	w.Lock()
+	w.subIds = make(map[string][]SubscribeArg)
+	args := make([]SubscribeArg, 0, len(w.subscriptions))
+	for arg, sub := range w.subscriptions {
+		sub.State, sub.Code, sub.Msg = SubscribePending, "", ""
+		args = append(args, arg)
+	}
+	w.Unlock()
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	return w.sendSubscribe("subscribe", args)
+}
+
+func (w *WebSocket) setState(arg SubscribeArg, state SubscriptionState, code, msg string) {
+	w.Lock()
+	defer w.Unlock()
+
+	if sub, ok := w.subscriptions[arg]; ok {
+		sub.State, sub.Code, sub.Msg = state, code, msg
+	}
+}
+
+// subscribed 处理订阅成功的响应
+func (w *WebSocket) subscribed(msg SocketMsg) {
+	if msg.Arg == nil {
+		return
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	if sub, ok := w.subscriptions[*msg.Arg]; ok {
+		sub.State, sub.Code, sub.Msg = SubscribeActive, "", ""
+	}
+
+	// 批量订阅时每个频道单独确认，全部确认后不再需要关联错误
+	args := w.subIds[msg.Id]
+	for i, arg := range args {
+		if arg == *msg.Arg {
+			args = append(args[:i:i], args[i+1:]...)
+			break
+		}
+	}
+
+	if len(args) == 0 {
+		delete(w.subIds, msg.Id)
+	} else {
+		w.subIds[msg.Id] = args
+	}
+}
+
+// subscribeFailed 根据id把错误关联到订阅请求，返回是否关联成功
+func (w *WebSocket) subscribeFailed(msg SocketMsg) bool {
+	if msg.Id == "" {
+		return false
+	}
+
+	w.Lock()
+	args, ok := w.subIds[msg.Id]
+	delete(w.subIds, msg.Id)
+	for _, arg := range args {
+		if sub, exists := w.subscriptions[arg]; exists && sub.State == SubscribePending {
+			sub.State, sub.Code, sub.Msg = SubscribeFailed, msg.Code, msg.Msg
+		}
+	}
+	w.Unlock()
+
+	if ok {
+		logx.Errorf("[ws] subscribe %v failed, code: %s, msg: %s", args, msg.Code, msg.Msg)
+	}
+
+	return ok
+}
@@ -0,0 +1,78 @@
+package okx
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func subscribeReply(req map[string]interface{}) []string {
+	switch req["op"] {
+	case "login":
+		return []string{`{"event":"login","code":"0","msg":""}`}
+	case "subscribe", "unsubscribe":
+		var resp []string
+		for _, arg := range req["args"].([]interface{}) {
+			if arg.(map[string]interface{})["instId"] == "BAD" {
+				return []string{fmt.Sprintf(`{"event":"error","id":"%s","code":"60018","msg":"Wrong URL or channel"}`, req["id"])}
+			}
+
+			b, _ := json.Marshal(arg)
+			resp = append(resp, fmt.Sprintf(`{"event":"%s","id":"%s","arg":%s}`, req["op"], req["id"], b))
+		}
+		return resp
+	}
+
+	return nil
+}
+
+func waitState(t *testing.T, ws *WebSocket, arg SubscribeArg, state SubscriptionState) Subscription {
+	for i := 0; i < 100; i++ {
+		if sub, ok := ws.Subscription(arg); ok && sub.State == state {
+			return sub
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	sub, _ := ws.Subscription(arg)
+	t.Fatalf("%v state = %v, want %v", arg, sub.State, state)
+	return sub
+}
+
+func TestSubscriptionState(t *testing.T) {
+	ws := startTestWebSocket(t, newTestServer(t, subscribeReply))
+
+	btc := SubscribeArg{Channel: "trades", InstId: "BTC-USDT"}
+	eth := SubscribeArg{Channel: "trades", InstId: "ETH-USDT"}
+	bad := SubscribeArg{Channel: "trades", InstId: "BAD"}
+
+	// 同一频道的多个产品不会互相覆盖
+	if err := ws.Subscribe("trades", &SubscribeMsg{Op: "subscribe", Args: []SubscribeArg{btc}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SubscribeArgs(eth); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SubscribeArgs(bad); err != nil {
+		t.Fatal(err)
+	}
+
+	waitState(t, ws, btc, SubscribeActive)
+	waitState(t, ws, eth, SubscribeActive)
+	if sub := waitState(t, ws, bad, SubscribeFailed); sub.Code != "60018" {
+		t.Errorf("failed subscription = %+v", sub)
+	}
+
+	if err := ws.Unsubscribe(eth); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := ws.Subscription(eth); ok {
+		t.Error("eth still subscribed")
+	}
+
+	if n := len(ws.Subscriptions()); n != 2 {
+		t.Errorf("subscriptions = %d, want 2", n)
+	}
+}
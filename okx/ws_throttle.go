@@ -0,0 +1,136 @@
+package okx
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrThrottled 触发连接或订阅限速，EventThrottled的err包装了此错误
+var ErrThrottled = errors.New("okx: websocket throttled")
+
+// OKX文档中websocket的限制
+const (
+	connectLimit     = 3              // 每个IP每秒新建连接数
+	opLimit          = 480            // 每个连接每小时subscribe、unsubscribe和login的总次数
+	maxSubscribeSize = 64*1024 - 1024 // 一次订阅请求中频道的总长度，保留一部分给请求的其它字段
+)
+
+// sharedConnThrottle 所有连接共享的建连限速，OKX按IP限制
+var sharedConnThrottle = newThrottle(connectLimit, time.Second)
+
+// throttle 并发安全的令牌桶
+type throttle struct {
+	mu sync.Mutex
+	b  *bucket
+}
+
+func newThrottle(count int, per time.Duration) *throttle {
+	return &throttle{b: newBucket(count, per)}
+}
+
+// reserve 获取一个令牌，不足时不扣除并返回需要等待的时间
+func (t *throttle) reserve() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.b.refill(time.Now())
+	if d := t.b.wait(1); d > 0 {
+		return d
+	}
+
+	t.b.tokens--
+	return 0
+}
+
+// beforeDial 在proxy之前执行建连限速，返回的函数作为recws的Proxy
+// recws没有提供其它拨号前的回调，自动重连也会调用Proxy，Start时包装已设置的Proxy，替换Proxy不会关闭限速
+func (w *WebSocket) beforeDial(proxy func(req *http.Request) (*url.URL, error)) func(req *http.Request) (*url.URL, error) {
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if err := w.waitConnect(); err != nil {
+			return nil, err
+		}
+
+		return proxy(req)
+	}
+}
+
+// waitConnect 建立连接之前获取令牌
+// 需要等待的时间不超过握手超时的一半时等待后继续，否则本次连接失败，由recws按重连间隔重试
+func (w *WebSocket) waitConnect() error {
+	for {
+		delay := w.connThrottle.reserve()
+		if delay <= 0 {
+			return nil
+		}
+
+		w.emit(EventThrottled, fmt.Errorf("%w: connect, wait %v", ErrThrottled, delay))
+		if delay > w.conn.HandshakeTimeout/2 {
+			return fmt.Errorf("%w: connect", ErrThrottled)
+		}
+
+		if err := sleepContext(w.ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// proxy 返回SetProxy设置的代理，没有设置时使用环境变量中的代理
+func (w *WebSocket) proxy(req *http.Request) (*url.URL, error) {
+	w.RLock()
+	proxyURL := w.proxyURL
+	w.RUnlock()
+
+	if proxyURL != nil {
+		return proxyURL, nil
+	}
+
+	return http.ProxyFromEnvironment(req)
+}
+
+// waitOp 发送subscribe、unsubscribe或login之前获取令牌，触发限速时等待
+func (w *WebSocket) waitOp(op string) error {
+	for {
+		delay := w.opThrottle.reserve()
+		if delay <= 0 {
+			return nil
+		}
+
+		w.emit(EventThrottled, fmt.Errorf("%w: %s, wait %v", ErrThrottled, op, delay))
+		if err := sleepContext(w.ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// chunkArgs 把频道按请求长度限制分成多组，每组用一次请求发送
+func chunkArgs(args []SubscribeArg, limit int) [][]SubscribeArg {
+	var chunks [][]SubscribeArg
+	var chunk []SubscribeArg
+	size := 0
+	for _, arg := range args {
+		b, _ := json.Marshal(arg)
+		n := len(b) + 1
+		if len(chunk) > 0 && size+n > limit {
+			chunks = append(chunks, chunk)
+			chunk, size = nil, 0
+		}
+
+		chunk = append(chunk, arg)
+		size += n
+	}
+
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
@@ -0,0 +1,156 @@
+package okx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChunkArgs(t *testing.T) {
+	var args []SubscribeArg
+	for i := 0; i < 10; i++ {
+		args = append(args, SubscribeArg{Channel: TradesChannel, InstId: fmt.Sprintf("INST-%d", i)})
+	}
+
+	// 每个频道编码后为38字节
+	chunks := chunkArgs(args, 100)
+	if len(chunks) != 5 || len(chunks[0]) != 2 {
+		t.Errorf("chunks = %v", chunks)
+	}
+
+	if chunks := chunkArgs(args, maxSubscribeSize); len(chunks) != 1 || len(chunks[0]) != 10 {
+		t.Errorf("chunks = %v", chunks)
+	}
+
+	if chunks := chunkArgs(nil, maxSubscribeSize); len(chunks) != 0 {
+		t.Errorf("chunks = %v", chunks)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	ws := InitWebSocket(SocketPubUrl)
+	defer ws.Close()
+
+	var events []error
+	ws.OnEvent(func(event ConnEvent, err error) {
+		if event == EventThrottled {
+			events = append(events, err)
+		}
+	})
+
+	ws.opThrottle = newThrottle(2, 100*time.Millisecond)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := ws.waitOp("subscribe"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if d := time.Since(start); d < 30*time.Millisecond {
+		t.Errorf("third op not delayed: %v", d)
+	}
+	if len(events) == 0 || !errors.Is(events[0], ErrThrottled) {
+		t.Errorf("events = %v", events)
+	}
+
+	// 等待时间超过握手超时的一半时本次连接失败
+	events = nil
+	ws.conn.HandshakeTimeout = 100 * time.Millisecond
+	ws.connThrottle = newThrottle(1, time.Second)
+	if err := ws.waitConnect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.waitConnect(); !errors.Is(err, ErrThrottled) || len(events) != 1 {
+		t.Errorf("err = %v, events = %v", err, events)
+	}
+
+	// 等待时间较短时等待后继续
+	ws.connThrottle = newThrottle(20, time.Second)
+	for i := 0; i < 21; i++ {
+		if err := ws.waitConnect(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ws.SetProxy("http://127.0.0.1:8080"); err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://ws.okx.com", nil)
+	if u, err := ws.proxy(req); err != nil || u.Host != "127.0.0.1:8080" {
+		t.Errorf("proxy = %v, err = %v", u, err)
+	}
+}
+
+func TestThrottleCustomProxy(t *testing.T) {
+	srv := newTestServer(t, subscribeReply)
+	ws := newTestWebSocket(t, srv)
+
+	// 替换Proxy后仍然限速
+	var proxied atomic.Int64
+	ws.conn.Proxy = func(req *http.Request) (*url.URL, error) {
+		proxied.Add(1)
+		return nil, nil
+	}
+
+	var throttled atomic.Int64
+	ws.OnEvent(func(event ConnEvent, err error) {
+		if event == EventThrottled {
+			throttled.Add(1)
+		}
+	})
+
+	ws.connThrottle = newThrottle(1, time.Hour)
+	ws.connThrottle.reserve()
+	ws.Start()
+
+	if throttled.Load() == 0 || proxied.Load() != 0 || ws.conn.IsConnected() {
+		t.Errorf("throttled = %d, proxied = %d", throttled.Load(), proxied.Load())
+	}
+}
+
+func TestSubscribeChunked(t *testing.T) {
+	var sent atomic.Int64
+	srv := newTestServer(t, func(req map[string]interface{}) []string {
+		if req["op"] == "subscribe" {
+			sent.Add(1)
+		}
+		return subscribeReply(req)
+	})
+
+	var args []SubscribeArg
+	for i := 0; i < 3000; i++ {
+		args = append(args, SubscribeArg{Channel: TradesChannel, InstId: fmt.Sprintf("INST-%d", i)})
+	}
+
+	ws := newTestWebSocket(t, srv)
+	if err := ws.SubscribeArgs(args...); err != nil {
+		t.Fatal(err)
+	}
+	ws.Start()
+
+	for i := 0; countState(ws, SubscribeActive) < len(args); i++ {
+		if i > 200 {
+			t.Fatalf("active = %d, sent = %d requests", countState(ws, SubscribeActive), sent.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if n := sent.Load(); n < 2 {
+		t.Errorf("sent %d requests, want chunks", n)
+	}
+}
+
+func countState(ws *WebSocket, state SubscriptionState) int {
+	n := 0
+	for _, sub := range ws.Subscriptions() {
+		if sub.State == state {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,206 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"strconv"
+)
+
+// ErrNotLoggedIn 私有连接尚未登录
+var ErrNotLoggedIn = errors.New("okx: websocket not logged in")
+
+// opMsg 交易类操作的请求
+type opMsg struct {
+	Id      string      `json:"id"`
+	Op      string      `json:"op"`
+	Args    interface{} `json:"args"`
+	ExpTime string      `json:"expTime,omitempty"`
+}
+
+// opResponse 交易类操作的响应，data中每一项带有sCode和sMsg
+type opResponse struct {
+	Id   string          `json:"id"`
+	Op   string          `json:"op"`
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+
+	err error // 连接断开或关闭
+}
+
+// Trader 下单接口，RestConfig和登录后的WebSocket都实现了该接口，可以按NetworkMode切换
+type Trader interface {
+	PlaceOrderWithContext(ctx context.Context, req *PlaceOrderRequest) (*Order, error)
+	BatchOrdersWithContext(ctx context.Context, data []*Order) ([]*Order, error)
+	CancelOrderWithContext(ctx context.Context, instId, ordId, clOrdId string) (*Order, error)
+	AmendOrderWithContext(ctx context.Context, req *AmendOrderRequest) (*AmendResult, error)
+}
+
+// NewTrader 根据mode返回使用rest或websocket下单的Trader
+func NewTrader(mode NetworkMode, c *RestConfig, ws *WebSocket) (Trader, error) {
+	switch mode {
+	case HttpMode:
+		if c == nil {
+			return nil, fmt.Errorf("rest config is required for http mode")
+		}
+		return c, nil
+	case SocketMode:
+		if ws == nil {
+			return nil, fmt.Errorf("websocket is required for socket mode")
+		}
+		return ws, nil
+	}
+
+	return nil, fmt.Errorf("invalid network mode: %d", mode)
+}
+
+// PlaceOrder 通过websocket下单
+func (w *WebSocket) PlaceOrder(req *PlaceOrderRequest) (*Order, error) {
+	return w.PlaceOrderWithContext(context.Background(), req)
+}
+
+// PlaceOrderWithContext 同PlaceOrder，使用ctx控制请求的取消与超时
+func (w *WebSocket) PlaceOrderWithContext(ctx context.Context, req *PlaceOrderRequest) (*Order, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var orders []*Order
+	if err := w.op(ctx, "order", []*PlaceOrderRequest{req}, OrderUrl, &orders); err != nil {
+		return nil, err
+	}
+
+	return orders[0], nil
+}
+
+// BatchOrders 通过websocket批量下单，部分失败时同时返回结果和*APIError
+func (w *WebSocket) BatchOrders(data []*Order) ([]*Order, error) {
+	return w.BatchOrdersWithContext(context.Background(), data)
+}
+
+// BatchOrdersWithContext 同BatchOrders，使用ctx控制请求的取消与超时
+func (w *WebSocket) BatchOrdersWithContext(ctx context.Context, data []*Order) ([]*Order, error) {
+	var orders []*Order
+	err := w.op(ctx, "batch-orders", data, BatchOrdersUrl, &orders)
+	return orders, err
+}
+
+// CancelOrder 通过websocket撤单
+func (w *WebSocket) CancelOrder(instId, ordId, clOrdId string) (*Order, error) {
+	return w.CancelOrderWithContext(context.Background(), instId, ordId, clOrdId)
+}
+
+// CancelOrderWithContext 同CancelOrder，使用ctx控制请求的取消与超时
+func (w *WebSocket) CancelOrderWithContext(ctx context.Context, instId, ordId, clOrdId string) (*Order, error) {
+	args := []Params{{"instId": instId, "ordId": ordId, "clOrdId": clOrdId}}
+
+	var orders []*Order
+	if err := w.op(ctx, "cancel-order", args, CancelOrderUrl, &orders); err != nil {
+		return nil, err
+	}
+
+	return orders[0], nil
+}
+
+// AmendOrder 通过websocket修改订单
+func (w *WebSocket) AmendOrder(req *AmendOrderRequest) (*AmendResult, error) {
+	return w.AmendOrderWithContext(context.Background(), req)
+}
+
+// AmendOrderWithContext 同AmendOrder，使用ctx控制请求的取消与超时
+func (w *WebSocket) AmendOrderWithContext(ctx context.Context, req *AmendOrderRequest) (*AmendResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var results []*AmendResult
+	if err := w.op(ctx, "amend-order", []*AmendOrderRequest{req}, AmendOrderUrl, &results); err != nil {
+		return nil, err
+	}
+
+	return results[0], nil
+}
+
+// op 发送交易类操作并等待响应，ctx没有设置超时时使用DefaultTimeout
+// path为对应的rest接口，用于判断是否需要携带expTime
+func (w *WebSocket) op(ctx context.Context, op string, args interface{}, path string, res interface{}) error {
+	if w.ctx.Err() != nil {
+		return ErrClosed
+	}
+
+	if w.auth == nil || !w.loggedIn.Load() {
+		return ErrNotLoggedIn
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+
+	id := strconv.FormatInt(w.nextId.Add(1), 10)
+	ch := make(chan *opResponse, 1)
+	w.Lock()
+	w.pending[id] = ch
+	w.Unlock()
+
+	defer func() {
+		w.Lock()
+		delete(w.pending, id)
+		w.Unlock()
+	}()
+
+	msg := &opMsg{Id: id, Op: op, Args: args, ExpTime: w.auth.expTime(path)}
+	if err := w.sendWSMessage(msg); err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-ch:
+		return resp.decode(res)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reply 把交易类操作的响应交给等待中的请求
+func (w *WebSocket) reply(id string, b []byte) {
+	var resp opResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return
+	}
+
+	w.RLock()
+	ch, ok := w.pending[id]
+	w.RUnlock()
+
+	if ok {
+		select {
+		case ch <- &resp:
+		default:
+		}
+	}
+}
+
+// decode 解析响应数据，code不为0时返回*APIError，批量操作部分失败时data依然会被解析
+func (r *opResponse) decode(res interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if len(r.Data) > 0 && res != nil {
+		if err := json.Unmarshal(r.Data, res); err != nil {
+			return err
+		}
+	}
+
+	if r.Code == CodeSuccess {
+		return nil
+	}
+
+	bean := &ResponseBean{Code: r.Code, Msg: r.Msg}
+	_ = json.Unmarshal(r.Data, &bean.Data)
+	return newAPIError(bean, 0, "WS", r.Op)
+}
@@ -0,0 +1,60 @@
+package okx
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestWebSocketTrade(t *testing.T) {
+	srv := newTestServer(t, func(req map[string]interface{}) []string {
+		switch req["op"] {
+		case "login":
+			return []string{`{"event":"login","code":"0","msg":""}`}
+		case "order":
+			return []string{
+				// 其它请求的响应不应影响当前请求
+				`{"id":"999","op":"order","code":"0","msg":"","data":[{"ordId":"0","sCode":"0","sMsg":""}]}`,
+				fmt.Sprintf(`{"id":"%s","op":"order","code":"0","msg":"","data":[{"clOrdId":"c1","ordId":"12345689","sCode":"0","sMsg":""}]}`, req["id"]),
+			}
+		case "batch-orders":
+			return []string{fmt.Sprintf(`{"id":"%s","op":"batch-orders","code":"2","msg":"","data":[{"ordId":"1","sCode":"0","sMsg":""},{"ordId":"","sCode":"51008","sMsg":"Insufficient balance"}]}`, req["id"])}
+		}
+		return nil
+	})
+
+	ws := startTestWebSocket(t, srv)
+	trader, err := NewTrader(SocketMode, nil, ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := trader.PlaceOrderWithContext(context.Background(), &PlaceOrderRequest{InstId: "BTC-USDT", TdMode: Cash, ClOrdId: "c1", Side: Buy, OrdType: Market, Sz: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order.OrdId != "12345689" {
+		t.Errorf("ordId = %s", order.OrdId)
+	}
+
+	orders, err := trader.BatchOrdersWithContext(context.Background(), []*Order{{InstId: "BTC-USDT"}, {InstId: "ETH-USDT"}})
+	if len(orders) != 2 || orders[0].OrdId != "1" {
+		t.Errorf("orders = %+v", orders)
+	}
+
+	if !IsInsufficientBalance(err) {
+		t.Errorf("err = %v, want insufficient balance", err)
+	}
+
+	if e, ok := AsAPIError(err); !ok || len(e.Items) != 1 || e.Items[0].Index != 1 {
+		t.Errorf("err = %#v", err)
+	}
+}
+
+func TestWebSocketNotLoggedIn(t *testing.T) {
+	ws := InitWebSocket(SocketPriGlobalUrl)
+	if _, err := ws.CancelOrder("BTC-USDT", "1", ""); err != ErrNotLoggedIn {
+		t.Errorf("err = %v, want ErrNotLoggedIn", err)
+	}
+}
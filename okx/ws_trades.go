@@ -0,0 +1,302 @@
+package okx
+
+import (
+	"github.com/zeromicro/go-zero/core/logx"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// vwapPlaces 成交均价在价格精度之外多保留的小数位数
+const vwapPlaces = 4
+
+// maxTradeFlushDelay 窗口结束后等待迟到成交的最长时间
+const maxTradeFlushDelay = time.Second
+
+// LargeTrade 名义价值达到阈值的成交
+type LargeTrade struct {
+	*Trade
+	Channel  string  // trades或trades-all
+	Notional Decimal // 名义价值，见Instrument.Notional
+}
+
+// TradeStats 一个产品在一个时间窗口内的成交统计
+type TradeStats struct {
+	InstId       string
+	Start        int64   // 窗口开始时间，毫秒
+	End          int64   // 窗口结束时间，毫秒，不包含
+	Count        int64   // 成交笔数，trades频道按聚合前的笔数计算
+	BuyVol       Decimal // 主动买入的数量，单位与sz相同
+	SellVol      Decimal // 主动卖出的数量
+	BuyNotional  Decimal // 主动买入的名义价值
+	SellNotional Decimal // 主动卖出的名义价值
+	Vwap         Decimal // 成交量加权平均价
+
+	pxSz    Decimal // 价格乘以数量之和，用于计算Vwap
+	pxScale int32   // 价格的最大小数位数
+}
+
+// TradeMonitor 统计ws上trades和trades-all频道的成交，按固定时间窗口汇总并检测大额成交
+// 窗口按成交时间划分，一个窗口的统计在该产品下一个窗口的第一笔成交到达时回调，
+// 没有新的成交时在窗口结束后按本地时间定时回调
+// 同一产品不要同时订阅trades和trades-all，否则会重复统计
+type TradeMonitor struct {
+	mu sync.Mutex
+
+	ws     *WebSocket
+	window time.Duration
+
+	insts      map[string]*Instrument
+	thresholds map[string]Decimal     // 大额成交的名义价值阈值，key为空时对所有产品生效
+	stats      map[string]*TradeStats // 每个产品当前窗口的统计
+	flushed    map[string]int64       // 每个产品最后一个定时回调的窗口的结束时间
+
+	onLarge []func(t *LargeTrade)
+	onStats []func(stats TradeStats)
+}
+
+// NewTradeMonitor 在ws上注册成交的处理函数，window为统计窗口的长度，小于等于0时不做统计
+func NewTradeMonitor(ws *WebSocket, window time.Duration) *TradeMonitor {
+	m := &TradeMonitor{
+		ws:         ws,
+		window:     window,
+		insts:      make(map[string]*Instrument),
+		thresholds: make(map[string]Decimal),
+		stats:      make(map[string]*TradeStats),
+		flushed:    make(map[string]int64),
+	}
+
+	ws.OnTrades("", m.handle)
+	ws.OnTradesAll("", m.handle)
+	if window > 0 {
+		go m.flushLoop()
+	}
+	return m
+}
+
+// Subscribe 订阅instIds的成交，channel为TradesChannel或TradesAllChannel
+func (m *TradeMonitor) Subscribe(channel string, instIds ...string) error {
+	args := make([]SubscribeArg, 0, len(instIds))
+	for _, instId := range instIds {
+		args = append(args, SubscribeArg{Channel: channel, InstId: instId})
+	}
+
+	return m.ws.SubscribeArgs(args...)
+}
+
+// SetInstruments 设置产品信息，用于按合约面值计算名义价值，未设置的产品按px*sz计算
+func (m *TradeMonitor) SetInstruments(insts ...*Instrument) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, inst := range insts {
+		m.insts[inst.InstId] = inst
+	}
+}
+
+// SetLargeTrade 设置instId大额成交的名义价值阈值，instId为空时作为所有产品的默认值，notional为0时取消
+func (m *TradeMonitor) SetLargeTrade(instId string, notional Decimal) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if notional.IsZero() {
+		delete(m.thresholds, instId)
+	} else {
+		m.thresholds[instId] = notional
+	}
+}
+
+// OnLargeTrade 注册大额成交的回调，在处理推送的goroutine中调用
+func (m *TradeMonitor) OnLargeTrade(fn func(t *LargeTrade)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onLarge = append(m.onLarge, fn)
+}
+
+// OnStats 注册窗口统计的回调，在处理推送或定时回调的goroutine中调用
+func (m *TradeMonitor) OnStats(fn func(stats TradeStats)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onStats = append(m.onStats, fn)
+}
+
+// Stats 返回instId当前窗口的统计，还没有成交时返回false
+func (m *TradeMonitor) Stats(instId string) (TradeStats, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.stats[instId]
+	if !ok {
+		return TradeStats{}, false
+	}
+
+	return stats.snapshot(), true
+}
+
+func (m *TradeMonitor) handle(push *Push[*Trade]) {
+	var large []*LargeTrade
+	var finished []TradeStats
+
+	m.mu.Lock()
+	for _, trade := range push.Data {
+		if trade.InstId == "" {
+			trade.InstId = push.Arg.InstId
+		}
+
+		px, err := trade.PxDecimal()
+		if err != nil {
+			logx.Errorf("[ws] trade %s: %v", trade.InstId, err)
+			continue
+		}
+
+		sz, err := trade.SzDecimal()
+		if err != nil {
+			logx.Errorf("[ws] trade %s: %v", trade.InstId, err)
+			continue
+		}
+
+		notional := px.Mul(sz)
+		if inst, ok := m.insts[trade.InstId]; ok {
+			if notional, err = inst.Notional(px, sz); err != nil {
+				logx.Errorf("[ws] trade %s: %v", trade.InstId, err)
+				continue
+			}
+		}
+
+		if stats := m.add(trade, px, sz, notional); stats != nil {
+			finished = append(finished, *stats)
+		}
+
+		threshold, ok := m.thresholds[trade.InstId]
+		if !ok {
+			threshold = m.thresholds[""]
+		}
+		if !threshold.IsZero() && notional.Cmp(threshold) >= 0 {
+			large = append(large, &LargeTrade{Trade: trade, Channel: push.Arg.Channel, Notional: notional})
+		}
+	}
+	onLarge, onStats := m.onLarge, m.onStats
+	m.mu.Unlock()
+
+	for _, stats := range finished {
+		for _, fn := range onStats {
+			fn(stats)
+		}
+	}
+
+	for _, t := range large {
+		for _, fn := range onLarge {
+			fn(t)
+		}
+	}
+}
+
+// add 把成交计入当前窗口，成交属于新的窗口时返回上一个窗口的统计
+func (m *TradeMonitor) add(trade *Trade, px, sz, notional Decimal) *TradeStats {
+	if m.window <= 0 {
+		return nil
+	}
+
+	ts, err := strconv.ParseInt(trade.Ts, 10, 64)
+	if err != nil {
+		logx.Errorf("[ws] trade %s: invalid ts %q", trade.InstId, trade.Ts)
+		return nil
+	}
+
+	var finished *TradeStats
+	window := m.window.Milliseconds()
+	cur, ok := m.stats[trade.InstId]
+	if ok && ts >= cur.End {
+		stats := cur.snapshot()
+		finished = &stats
+	}
+
+	// 迟到的成交计入当前窗口，已经定时回调的窗口不再重复统计
+	if !ok || ts >= cur.End {
+		if end := m.flushed[trade.InstId]; !ok && ts < end {
+			ts = end
+		}
+		start := ts - ts%window
+		cur = &TradeStats{InstId: trade.InstId, Start: start, End: start + window}
+		m.stats[trade.InstId] = cur
+	}
+
+	count := int64(1)
+	if n, err := strconv.ParseInt(trade.Count, 10, 64); err == nil && n > 0 {
+		count = n
+	}
+
+	cur.Count += count
+	if trade.Side == Sell {
+		cur.SellVol = cur.SellVol.Add(sz)
+		cur.SellNotional = cur.SellNotional.Add(notional)
+	} else {
+		cur.BuyVol = cur.BuyVol.Add(sz)
+		cur.BuyNotional = cur.BuyNotional.Add(notional)
+	}
+
+	cur.pxSz = cur.pxSz.Add(px.Mul(sz))
+	if px.scale > cur.pxScale {
+		cur.pxScale = px.scale
+	}
+
+	return finished
+}
+
+// flushLoop 定时回调已经结束的窗口，ws关闭后退出
+func (m *TradeMonitor) flushLoop() {
+	delay := m.window / 2
+	if delay > maxTradeFlushDelay {
+		delay = maxTradeFlushDelay
+	}
+
+	ticker := time.NewTicker(delay)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ws.ctx.Done():
+			return
+		case <-ticker.C:
+			m.flush(time.Now().Add(-delay))
+		}
+	}
+}
+
+// flush 回调结束时间不晚于before的窗口
+func (m *TradeMonitor) flush(before time.Time) {
+	var finished []TradeStats
+
+	m.mu.Lock()
+	for instId, cur := range m.stats {
+		if cur.End > before.UnixMilli() {
+			continue
+		}
+
+		finished = append(finished, cur.snapshot())
+		m.flushed[instId] = cur.End
+		delete(m.stats, instId)
+	}
+	onStats := m.onStats
+	m.mu.Unlock()
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].InstId < finished[j].InstId
+	})
+
+	for _, stats := range finished {
+		for _, fn := range onStats {
+			fn(stats)
+		}
+	}
+}
+
+func (s *TradeStats) snapshot() TradeStats {
+	stats := *s
+	if vol := s.BuyVol.Add(s.SellVol); !vol.IsZero() {
+		stats.Vwap = s.pxSz.Div(vol, s.pxScale+vwapPlaces)
+	}
+
+	return stats
+}
@@ -0,0 +1,134 @@
+package okx
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestInstrumentNotional(t *testing.T) {
+	px, sz := MustDecimal("40000"), MustDecimal("3")
+	cases := []struct {
+		inst *Instrument
+		want string
+	}{
+		{&Instrument{InstId: "BTC-USDT", InstType: SPOT}, "120000"},
+		{&Instrument{InstId: "BTC-USDT-SWAP", InstType: SWAP, CtType: "linear", CtVal: "0.01", CtMult: "1"}, "1200"},
+		{&Instrument{InstId: "BTC-USD-SWAP", InstType: SWAP, CtType: "inverse", CtVal: "100", CtMult: "1"}, "300"},
+		{&Instrument{InstId: "ETH-USDT-SWAP", InstType: SWAP, CtType: "linear", CtVal: "0.1"}, "12000"},
+	}
+
+	for _, c := range cases {
+		got, err := c.inst.Notional(px, sz)
+		if err != nil || !got.Equal(MustDecimal(c.want)) {
+			t.Errorf("%s notional = %s, %v, want %s", c.inst.InstId, got, err, c.want)
+		}
+	}
+}
+
+func pushTrade(ws *WebSocket, channel, instId, side, px, sz string, ts int64) {
+	ws.handle([]byte(fmt.Sprintf(`{"arg":{"channel":"%s","instId":"%s"},"data":[{"instId":"%s","tradeId":"1","px":"%s","sz":"%s","side":"%s","ts":"%d","count":"1"}]}`,
+		channel, instId, instId, px, sz, side, ts)))
+}
+
+func TestTradeMonitor(t *testing.T) {
+	ws := InitWebSocket(SocketPubUrl)
+	m := NewTradeMonitor(ws, time.Minute)
+	m.SetInstruments(&Instrument{InstId: "BTC-USDT-SWAP", InstType: SWAP, CtType: "linear", CtVal: "0.01", CtMult: "1"})
+	m.SetLargeTrade("", MustDecimal("100000"))
+	m.SetLargeTrade("BTC-USDT-SWAP", MustDecimal("10000"))
+
+	var large []string
+	m.OnLargeTrade(func(t *LargeTrade) {
+		large = append(large, t.InstId+"/"+t.Channel+"/"+t.Notional.String())
+	})
+
+	var stats []TradeStats
+	m.OnStats(func(s TradeStats) {
+		stats = append(stats, s)
+	})
+
+	const minute = 60000
+	pushTrade(ws, TradesChannel, "BTC-USDT", "buy", "40000", "1", 10*minute)
+	pushTrade(ws, TradesChannel, "BTC-USDT", "sell", "40100", "3", 10*minute+1)
+	pushTrade(ws, TradesChannel, "BTC-USDT-SWAP", "buy", "40000", "30", 10*minute+2)
+	pushTrade(ws, TradesChannel, "BTC-USDT-SWAP", "sell", "40000", "20", 10*minute+3)
+
+	cur, ok := m.Stats("BTC-USDT")
+	if !ok || cur.Count != 2 || !cur.BuyVol.Equal(MustDecimal("1")) || !cur.SellNotional.Equal(MustDecimal("120300")) {
+		t.Errorf("stats = %+v", cur)
+	}
+
+	// 下一个窗口的成交触发上一个窗口的回调
+	pushTrade(ws, TradesAllChannel, "BTC-USDT", "buy", "40200", "0.1", 11*minute)
+	if len(stats) != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	s := stats[0]
+	if s.InstId != "BTC-USDT" || s.Start != 10*minute || s.End != 11*minute || s.Count != 2 {
+		t.Errorf("stats = %+v", s)
+	}
+	if !s.Vwap.Equal(MustDecimal("40075")) || !s.SellVol.Equal(MustDecimal("3")) {
+		t.Errorf("vwap = %s, sell vol = %s", s.Vwap, s.SellVol)
+	}
+
+	want := "[BTC-USDT/trades/120300 BTC-USDT-SWAP/trades/12000.00]"
+	if got := fmt.Sprint(large); got != want {
+		t.Errorf("large = %s, want %s", got, want)
+	}
+}
+
+func TestTradeMonitorFlush(t *testing.T) {
+	ws := InitWebSocket(SocketPubUrl)
+	defer ws.Close()
+
+	m := NewTradeMonitor(ws, time.Minute)
+	var stats []TradeStats
+	m.OnStats(func(s TradeStats) {
+		stats = append(stats, s)
+	})
+
+	const minute = 60000
+	pushTrade(ws, TradesChannel, "BTC-USDT", Buy, "40000", "1", 10*minute)
+	m.flush(time.UnixMilli(11*minute - 1))
+	if len(stats) != 0 {
+		t.Fatalf("window not finished, stats = %+v", stats)
+	}
+
+	m.flush(time.UnixMilli(11 * minute))
+	if len(stats) != 1 || stats[0].Start != 10*minute || stats[0].Count != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if _, ok := m.Stats("BTC-USDT"); ok {
+		t.Error("flushed window should be removed")
+	}
+
+	// 已经回调的窗口的迟到成交计入下一个窗口
+	pushTrade(ws, TradesChannel, "BTC-USDT", Sell, "40000", "2", 11*minute-1)
+	cur, ok := m.Stats("BTC-USDT")
+	if !ok || cur.Start != 11*minute || !cur.SellVol.Equal(MustDecimal("2")) {
+		t.Errorf("stats = %+v", cur)
+	}
+}
+
+func TestTradeMonitorFlushTimer(t *testing.T) {
+	ws := InitWebSocket(SocketPubUrl)
+	defer ws.Close()
+
+	m := NewTradeMonitor(ws, 50*time.Millisecond)
+	stats := make(chan TradeStats, 1)
+	m.OnStats(func(s TradeStats) {
+		stats <- s
+	})
+
+	pushTrade(ws, TradesChannel, "BTC-USDT", Buy, "40000", "1", time.Now().UnixMilli())
+	select {
+	case s := <-stats:
+		if s.InstId != "BTC-USDT" || s.Count != 1 {
+			t.Errorf("stats = %+v", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("window of a quiet period should be flushed")
+	}
+}